@@ -0,0 +1,198 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"io"
+)
+
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+// apngHeaderSize is the length of the PNG signature and IHDR chunk that
+// precede the acTL chunk
+const apngHeaderSize = 8 + 12 + 13
+
+// EncodeAPNG consumes frames and writes them to w as an infinitely looping
+// animated PNG, with each frame shown for 1/frameRate seconds. Frames are
+// compressed and written as they arrive, so memory use does not grow with
+// the length of the animation; w seeks back to fill in the frame count once
+// the channel closes. If encoding stops early with an error, the count
+// still covers the frames already written. A single frame still decodes as
+// a plain static PNG.
+func EncodeAPNG(frames <-chan image.Image, w io.WriteSeeker, frameRate int) error {
+	start, err := w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	n, err := encodeAPNG(frames, w, frameRate)
+	if n == 0 {
+		return err
+	}
+	if perr := patchFrameCount(w, start, n); err == nil {
+		err = perr
+	}
+	return err
+}
+
+// patchFrameCount rewrites the acTL chunk of the APNG that starts at start
+// in w, which has a fixed size, to announce n frames, and seeks back to
+// where w was
+func patchFrameCount(w io.WriteSeeker, start int64, n int) error {
+	end, err := w.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Seek(start+apngHeaderSize, io.SeekStart); err != nil {
+		return err
+	}
+	pw := &pngWriter{w: w}
+	pw.chunk("acTL", actlData(n))
+	if pw.err != nil {
+		return pw.err
+	}
+	_, err = w.Seek(end, io.SeekStart)
+	return err
+}
+
+// encodeAPNG writes frames to w as they arrive, with a frame count of zero
+// in the acTL chunk, and returns how many it wrote in full
+func encodeAPNG(frames <-chan image.Image, w io.Writer, frameRate int) (int, error) {
+	if frameRate <= 0 {
+		frameRate = 30
+	}
+
+	pw := &pngWriter{w: w}
+	var size image.Point
+	var seq uint32
+	n := 0
+	for frame := range frames {
+		b := frame.Bounds()
+		if n == 0 {
+			size = b.Size()
+			writeAPNGHeader(pw, size)
+		} else if b.Size() != size {
+			return n, fmt.Errorf("frame %d is %v, want %v", n, b.Size(), size)
+		}
+
+		data, err := compressRGBA(frame)
+		if err != nil {
+			return n, err
+		}
+
+		fctl := make([]byte, 26)
+		binary.BigEndian.PutUint32(fctl[0:], seq)
+		binary.BigEndian.PutUint32(fctl[4:], uint32(size.X))
+		binary.BigEndian.PutUint32(fctl[8:], uint32(size.Y))
+		// x/y offsets are zero; delay is 1/frameRate seconds
+		binary.BigEndian.PutUint16(fctl[20:], 1)
+		binary.BigEndian.PutUint16(fctl[22:], uint16(frameRate))
+		// dispose_op and blend_op are 0: none and source
+		pw.chunk("fcTL", fctl)
+		seq++
+
+		if n == 0 {
+			// The first frame doubles as the default image
+			pw.chunk("IDAT", data)
+		} else {
+			fdat := make([]byte, 4+len(data))
+			binary.BigEndian.PutUint32(fdat, seq)
+			copy(fdat[4:], data)
+			pw.chunk("fdAT", fdat)
+			seq++
+		}
+		n++
+		if pw.err != nil {
+			return n, pw.err
+		}
+	}
+	if n == 0 {
+		return 0, errors.New("no frames to encode")
+	}
+
+	pw.chunk("IEND", nil)
+	return n, pw.err
+}
+
+// writeAPNGHeader writes the PNG signature, the IHDR chunk for an 8-bit
+// RGBA image of the given size, and an acTL chunk to be patched with the
+// frame count
+func writeAPNGHeader(pw *pngWriter, size image.Point) {
+	pw.write(pngSignature)
+
+	// IHDR: 8-bit RGBA, no interlacing
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:], uint32(size.X))
+	binary.BigEndian.PutUint32(ihdr[4:], uint32(size.Y))
+	ihdr[8] = 8
+	ihdr[9] = 6
+	pw.chunk("IHDR", ihdr)
+
+	pw.chunk("acTL", actlData(0))
+}
+
+// actlData returns the acTL chunk data: the frame count, looping forever
+func actlData(count int) []byte {
+	actl := make([]byte, 8)
+	binary.BigEndian.PutUint32(actl[0:], uint32(count))
+	return actl
+}
+
+// compressRGBA returns the zlib-compressed scanlines of img as 8-bit
+// non-premultiplied RGBA with no filtering, the same conversion image/png
+// applies.
+func compressRGBA(img image.Image) ([]byte, error) {
+	b := img.Bounds()
+	row := make([]byte, 1+4*b.Dx())
+
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			i := 1 + 4*(x-b.Min.X)
+			row[i], row[i+1], row[i+2], row[i+3] = c.R, c.G, c.B, c.A
+		}
+		if _, err := zw.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pngWriter writes PNG chunks, keeping the first error
+type pngWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (pw *pngWriter) write(p []byte) {
+	if pw.err == nil {
+		_, pw.err = pw.w.Write(p)
+	}
+}
+
+func (pw *pngWriter) chunk(typ string, data []byte) {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], typ)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	var footer [4]byte
+	binary.BigEndian.PutUint32(footer[:], crc.Sum32())
+
+	pw.write(header[:])
+	pw.write(data)
+	pw.write(footer[:])
+}
@@ -0,0 +1,83 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"os"
+	"testing"
+)
+
+func threeFrames() <-chan image.Image {
+	frames := make(chan image.Image, 3)
+	for i := 0; i < 3; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, 8, 4))
+		img.Pix[0] = uint8(i * 80)
+		frames <- img
+	}
+	close(frames)
+	return frames
+}
+
+// actlFrames returns num_frames from the acTL chunk of an APNG
+func actlFrames(t *testing.T, data []byte) uint32 {
+	t.Helper()
+	for p := len(pngSignature); p+8 <= len(data); {
+		n := int(binary.BigEndian.Uint32(data[p:]))
+		if string(data[p+4:p+8]) == "acTL" {
+			return binary.BigEndian.Uint32(data[p+8:])
+		}
+		p += 12 + n
+	}
+	t.Fatal("no acTL chunk")
+	return 0
+}
+
+func TestEncodeAPNGFrameCount(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "*.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := EncodeAPNG(threeFrames(), f, 10); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := actlFrames(t, data); n != 3 {
+		t.Fatalf("acTL num_frames = %d, want 3", n)
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Fatalf("default image does not decode: %v", err)
+	}
+}
+
+func TestEncodeAPNGErrorKeepsFrameCount(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "*.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// The third frame is the wrong size, which stops encoding after two
+	frames := make(chan image.Image, 3)
+	frames <- image.NewRGBA(image.Rect(0, 0, 8, 4))
+	frames <- image.NewRGBA(image.Rect(0, 0, 8, 4))
+	frames <- image.NewRGBA(image.Rect(0, 0, 4, 4))
+	close(frames)
+	if err := EncodeAPNG(frames, f, 10); err == nil {
+		t.Fatal("expected an error for a frame of a different size")
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := actlFrames(t, data); n != 2 {
+		t.Fatalf("acTL num_frames = %d, want the 2 frames written", n)
+	}
+}
@@ -0,0 +1,25 @@
+package nimsforestsprites
+
+import "image/color"
+
+// attachmentColor is the line from a process to the center of its land
+var attachmentColor = color.RGBA{200, 210, 200, 110}
+
+// SetAttachmentLinesEnabled turns on a thin line from each process to the
+// center of the land named by its LandID, showing which land owns a
+// process that has wandered. Processes whose LandID matches no land get
+// no line.
+func (r *Renderer) SetAttachmentLinesEnabled(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attachments = enabled
+}
+
+// landsByID indexes lands by ID
+func landsByID(lands []Land) map[string]Land {
+	byID := make(map[string]Land, len(lands))
+	for _, land := range lands {
+		byID[land.ID] = land
+	}
+	return byID
+}
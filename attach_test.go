@@ -0,0 +1,40 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestAttachmentLineJoinsProcessToLand(t *testing.T) {
+	r := newTestRenderer(t, Options{})
+	lands := []Land{{ID: "a", Type: "forest"}}
+	at := func(landID string) State {
+		return &staticState{lands: lands, processes: []Process{
+			{ID: "p", LandID: landID, Type: "tree", X: 2},
+		}}
+	}
+
+	// The process has wandered two tiles right of its land, so the line
+	// crosses the background between them at x = 200
+	plain := r.RenderAt(at("a"), 0).(*image.RGBA)
+	r.SetAttachmentLinesEnabled(true)
+	lined := r.RenderAt(at("a"), 0).(*image.RGBA)
+	changed := 0
+	for y := 100; y < 164; y++ {
+		if lined.RGBAAt(200, y) != plain.RGBAAt(200, y) {
+			changed++
+		}
+	}
+	if changed == 0 {
+		t.Error("no attachment line between the process and its land")
+	}
+
+	// A LandID matching no land gets no line
+	r.SetAttachmentLinesEnabled(false)
+	plain = r.RenderAt(at("missing"), 0).(*image.RGBA)
+	r.SetAttachmentLinesEnabled(true)
+	if img := r.RenderAt(at("missing"), 0).(*image.RGBA); !bytes.Equal(img.Pix, plain.Pix) {
+		t.Error("drew an attachment line for a process with an unknown LandID")
+	}
+}
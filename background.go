@@ -0,0 +1,79 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"image/draw"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// BackgroundMode controls how a background image is fitted to the frame
+type BackgroundMode int
+
+const (
+	// BackgroundTile repeats the image from the top-left corner
+	BackgroundTile BackgroundMode = iota
+	// BackgroundStretch scales the image to cover the whole frame
+	BackgroundStretch
+	// BackgroundCenter draws the image unscaled in the middle of the
+	// frame; the palette background shows around a smaller image
+	BackgroundCenter
+)
+
+// SetBackgroundImage draws img behind the lands instead of the flat
+// palette background. The image is fitted to the frame once, here, so
+// later changes to img are not picked up. A nil image restores the flat
+// background.
+func (r *Renderer) SetBackgroundImage(img image.Image, mode BackgroundMode) {
+	var composed *image.RGBA
+	if img != nil && !img.Bounds().Empty() {
+		composed = composeBackground(img, mode, r.opts.Width, r.opts.Height)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.background = composed
+}
+
+// composeBackground fits src to a w x h frame. Areas the image doesn't
+// cover are left transparent so the flat background shows through.
+func composeBackground(src image.Image, mode BackgroundMode, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	sb := src.Bounds()
+
+	switch mode {
+	case BackgroundStretch:
+		// Nearest-neighbor keeps the cost down; the background is fitted
+		// once, not per frame
+		for y := 0; y < h; y++ {
+			sy := sb.Min.Y + y*sb.Dy()/h
+			for x := 0; x < w; x++ {
+				sx := sb.Min.X + x*sb.Dx()/w
+				dst.Set(x, y, src.At(sx, sy))
+			}
+		}
+	case BackgroundCenter:
+		at := image.Pt((w-sb.Dx())/2, (h-sb.Dy())/2)
+		draw.Draw(dst, sb.Sub(sb.Min).Add(at), src, sb.Min, draw.Src)
+	default:
+		for y := 0; y < h; y += sb.Dy() {
+			for x := 0; x < w; x += sb.Dx() {
+				draw.Draw(dst, sb.Sub(sb.Min).Add(image.Pt(x, y)), src, sb.Min, draw.Src)
+			}
+		}
+	}
+	return dst
+}
+
+// backgroundImage returns the GPU copy of the composed background,
+// uploading it again only when the background has changed
+func (g *ebitenGame) backgroundImage(bg *image.RGBA) *ebiten.Image {
+	if g.bgSource != bg {
+		if g.bgImage != nil {
+			g.bgImage.Dispose()
+		}
+		g.bgSource = bg
+		g.bgImage = ebiten.NewImageFromImage(bg)
+	}
+	return g.bgImage
+}
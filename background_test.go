@@ -0,0 +1,38 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestBackgroundImageModes(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	small := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	draw.Draw(small, small.Bounds(), &image.Uniform{red}, image.Point{}, draw.Src)
+	state := &staticState{lands: []Land{{ID: "a", Type: "forest"}}}
+	r := newTestRenderer(t, Options{})
+
+	r.SetBackgroundImage(small, BackgroundStretch)
+	img := r.RenderAt(state, 0).(*image.RGBA)
+	for _, pt := range []image.Point{{0, 0}, {319, 0}, {0, 239}, {319, 239}} {
+		if got := img.RGBAAt(pt.X, pt.Y); got != red {
+			t.Errorf("stretched: corner %v is %v, want red", pt, got)
+		}
+	}
+	if img.RGBAAt(131, 131) == red {
+		t.Error("stretched: the background covers the land")
+	}
+
+	// A centered image smaller than the frame is letterboxed with the
+	// palette background. Without lands, nothing covers the middle.
+	r.SetBackgroundImage(small, BackgroundCenter)
+	img = r.RenderAt(&staticState{}, 0).(*image.RGBA)
+	if got := img.RGBAAt(0, 0); got != DefaultPalette().Background {
+		t.Errorf("centered: corner is %v, want the palette background", got)
+	}
+	if got := img.RGBAAt(160, 120); got != red {
+		t.Errorf("centered: middle is %v, want red", got)
+	}
+}
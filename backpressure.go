@@ -0,0 +1,72 @@
+package nimsforestsprites
+
+// Backpressure selects what happens when a frame channel is full.
+type Backpressure int
+
+const (
+	// DropNewest discards the frame being sent. Memory stays bounded and
+	// the consumer sees slightly stale frames while it catches up.
+	DropNewest Backpressure = iota
+	// DropOldest discards the oldest queued frame to make room. Memory
+	// stays bounded and the consumer always gets the freshest frame, which
+	// suits live previews.
+	DropOldest
+	// Block waits until the consumer takes the frame. Nothing is lost, but
+	// a slow consumer slows rendering down with it, which suits lossless
+	// recorders.
+	Block
+)
+
+// sendFrame delivers frame on ch according to policy and returns how many
+// frames were discarded. Block gives up when done or cancel is closed;
+// either may be nil.
+func sendFrame[T any](ch chan T, frame T, policy Backpressure, done, cancel <-chan struct{}) int {
+	switch policy {
+	case Block:
+		select {
+		case ch <- frame:
+			return 0
+		case <-done:
+			return 1
+		case <-cancel:
+			return 1
+		}
+	case DropOldest:
+		select {
+		case ch <- frame:
+			return 0
+		default:
+		}
+		dropped := 0
+		select {
+		case <-ch:
+			dropped++
+		default:
+		}
+		select {
+		case ch <- frame:
+		default:
+			dropped++
+		}
+		return dropped
+	default:
+		select {
+		case ch <- frame:
+			return 0
+		default:
+			return 1
+		}
+	}
+}
+
+// drainFrames discards every frame queued on ch and returns how many there
+// were
+func drainFrames[T any](ch chan T) int {
+	for n := 0; ; n++ {
+		select {
+		case <-ch:
+		default:
+			return n
+		}
+	}
+}
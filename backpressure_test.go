@@ -0,0 +1,74 @@
+package nimsforestsprites
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBlockSlowConsumerDropsNothing(t *testing.T) {
+	r := newTestRenderer(t, Options{FrameRate: 60, Backpressure: Block, FrameBuffer: 1})
+	r.Update(NewMockStateSeed(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	frames := r.FramesWithInfo(ctx)
+	lastTick := 0
+	for i := 0; i < 6; i++ {
+		f := <-frames
+		if f.DroppedSince != 0 {
+			t.Fatalf("frame %d reports %d dropped", i, f.DroppedSince)
+		}
+		if f.Tick != lastTick+1 {
+			t.Fatalf("frame %d has tick %d after %d", i, f.Tick, lastTick)
+		}
+		lastTick = f.Tick
+		time.Sleep(40 * time.Millisecond) // Slower than the frame rate
+	}
+	if n := r.DroppedFrames(); n != 0 {
+		t.Fatalf("DroppedFrames = %d, want 0", n)
+	}
+
+	// A blocked send gives up once the context is cancelled
+	cancel()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-frames:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Frames did not close after cancel")
+		}
+	}
+}
+
+func TestDroppedSinceCountsFramesSkippedForSlowConsumer(t *testing.T) {
+	clock := newFakeClock()
+	r := newTestRenderer(t, Options{FrameRate: 30, FrameBuffer: 1, Clock: clock})
+	r.Update(NewMockStateSeed(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	frames := r.FramesWithInfo(ctx)
+	ticker := <-clock.tickers
+
+	// The first frame fills the buffer and the next ones are dropped. Each
+	// fire waits for the loop to finish the previous tick.
+	for i := 0; i < 5; i++ {
+		clock.fire(ticker)
+	}
+	first := <-frames
+	if first.Tick != 1 || first.DroppedSince != 0 {
+		t.Fatalf("first frame has tick %d and %d dropped, want tick 1 and none", first.Tick, first.DroppedSince)
+	}
+
+	clock.fire(ticker)
+	next := <-frames
+	if next.DroppedSince == 0 || next.DroppedSince != next.Tick-2 {
+		t.Fatalf("frame at tick %d reports %d dropped, want every tick since the first", next.Tick, next.DroppedSince)
+	}
+	if n := r.DroppedFrames(); n < int64(next.DroppedSince) {
+		t.Fatalf("DroppedFrames = %d, want at least %d", n, next.DroppedSince)
+	}
+}
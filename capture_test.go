@@ -0,0 +1,41 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"testing"
+)
+
+// benchmarkCapture measures the capture side of a GPU frame with and
+// without Options.DoubleBuffer. ReadPixels needs a running ebiten game, so
+// a copy from a rendered frame stands in for it; the consumer takes each
+// frame off frameCh and hands its buffer back.
+func benchmarkCapture(b *testing.B, double bool) {
+	r := newTestRenderer(b, Options{Width: 640, Height: 360, DoubleBuffer: double})
+	pixels := r.RenderAt(NewMockStateSeed(1), 0).(*image.RGBA).Pix
+	bounds := image.Rect(0, 0, 640, 360)
+	g := &ebitenGame{renderer: r}
+	if double {
+		g.buffers = [2]*image.RGBA{image.NewRGBA(bounds), image.NewRGBA(bounds)}
+		g.free = make(chan *image.RGBA, len(g.buffers))
+		for _, buf := range g.buffers {
+			g.free <- buf
+		}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var img *image.RGBA
+		if double {
+			img = g.nextBuffer()
+		} else {
+			img = image.NewRGBA(bounds)
+		}
+		copy(img.Pix, pixels)
+		g.sendCapture(img)
+		g.release(<-r.frameCh)
+	}
+}
+
+func BenchmarkCaptureAllocating(b *testing.B)   { benchmarkCapture(b, false) }
+func BenchmarkCaptureDoubleBuffer(b *testing.B) { benchmarkCapture(b, true) }
@@ -0,0 +1,35 @@
+package nimsforestsprites
+
+import "time"
+
+// Clock is the renderer's source of time. The Frames loop, interpolation,
+// render timing, Recorder, and PlayRecording read it, so tests can inject a
+// fake through Options.Clock and step frames deterministically. All
+// waiting goes through NewTicker. Timeouts that guard against a hung GPU
+// or ebiten shutdown always use real time.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers the current time on C at a regular interval until
+// stopped
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// realClock is the wall clock, used when Options.Clock is nil
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
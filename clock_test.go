@@ -0,0 +1,152 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock only moves when advanced. Tickers it creates are handed to the
+// test on tickers and fire only when the test sends on them.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers chan *fakeTicker
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1000, 0), tickers: make(chan *fakeTicker, 4)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) NewTicker(d time.Duration) Ticker {
+	t := &fakeTicker{c: make(chan time.Time), d: d}
+	c.tickers <- t
+	return t
+}
+
+// fire advances the clock by the ticker's interval and delivers the tick
+func (c *fakeClock) fire(t *fakeTicker) {
+	c.mu.Lock()
+	c.now = c.now.Add(t.d)
+	now := c.now
+	c.mu.Unlock()
+	t.c <- now
+}
+
+// advance moves the clock forward by d without firing any ticker
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type fakeTicker struct {
+	c chan time.Time
+	d time.Duration
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.c }
+func (t *fakeTicker) Stop()               {}
+
+func TestFakeClockDrivesFrames(t *testing.T) {
+	const n = 5
+	clock := newFakeClock()
+	r := newTestRenderer(t, Options{FrameRate: 30, Clock: clock})
+	r.Update(NewMockStateSeed(1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	frames := r.Frames(ctx)
+
+	var ticker *fakeTicker
+	select {
+	case ticker = <-clock.tickers:
+	case <-time.After(time.Second):
+		t.Fatal("Frames never created a ticker")
+	}
+	if ticker.d != time.Second/30 {
+		t.Fatalf("ticker interval %v, want %v", ticker.d, time.Second/30)
+	}
+
+	for i := 0; i < n; i++ {
+		clock.fire(ticker)
+		select {
+		case <-frames:
+		case <-time.After(time.Second):
+			t.Fatalf("no frame after tick %d", i+1)
+		}
+	}
+
+	// Without another tick, nothing more comes out
+	select {
+	case <-frames:
+		t.Fatal("frame produced without a tick")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if got := r.Stats().FramesProduced; got != n {
+		t.Fatalf("FramesProduced = %d, want %d", got, n)
+	}
+}
+
+func TestAnimationFollowsElapsedTimeNotFrameRate(t *testing.T) {
+	state := NewMockStateSeed(2)
+	slow := newTestRenderer(t, Options{FrameRate: 15})
+	fast := newTestRenderer(t, Options{FrameRate: 60})
+
+	// One second at each rate
+	var a, b image.Image
+	for i := 0; i < 15; i++ {
+		a = slow.Render(state)
+	}
+	for i := 0; i < 60; i++ {
+		b = fast.Render(state)
+	}
+	if !bytes.Equal(a.(*image.RGBA).Pix, b.(*image.RGBA).Pix) {
+		t.Error("frames after one second at 15 and 60 fps differ")
+	}
+
+	// A stall advances the animation by at most maxAnimationStep
+	slow.mu.RLock()
+	before := slow.elapsed
+	slow.mu.RUnlock()
+	slow.Advance(10 * time.Second)
+	slow.mu.RLock()
+	step := slow.elapsed - before
+	slow.mu.RUnlock()
+	if step != maxAnimationStep {
+		t.Errorf("Advance(10s) moved the animation %v, want %v", step, maxAnimationStep)
+	}
+}
+
+func TestResetTickRestartsAnimation(t *testing.T) {
+	r := newTestRenderer(t, Options{})
+	state := NewMockStateSeed(5)
+	for i := 0; i < 7; i++ {
+		r.Render(state)
+	}
+	if got := r.Tick(); got != 7 {
+		t.Fatalf("Tick() = %d after 7 renders", got)
+	}
+
+	// After a reset the next frame has the pulse and bounce of the first
+	r.ResetTick()
+	if got := r.Tick(); got != 0 {
+		t.Fatalf("Tick() = %d after ResetTick", got)
+	}
+	frame := r.Render(state).(*image.RGBA)
+	if !bytes.Equal(frame.Pix, r.RenderAt(state, 1).(*image.RGBA).Pix) {
+		t.Error("frame after ResetTick differs from the frame at tick 1")
+	}
+	if bytes.Equal(frame.Pix, r.RenderAt(state, 8).(*image.RGBA).Pix) {
+		t.Error("frame after ResetTick matches tick 8, as if it was never reset")
+	}
+}
@@ -0,0 +1,154 @@
+package nimsforestsprites
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+)
+
+// MinColorDifference is the smallest CIE76 color difference (ΔE) Validate
+// accepts between two type colors under any simulated color vision
+// deficiency. Around 10 is where similar colors start to blur together
+// on screen.
+const MinColorDifference = 12.0
+
+// Warning reports two type colors that are hard to tell apart
+type Warning struct {
+	Kind       string  // "land" or "process"
+	A, B       string  // Type names; "default" is the fallback color
+	Deficiency string  // Simulated vision, e.g. "deuteranopia"
+	Difference float64 // Simulated ΔE between the two colors
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s colors %q and %q differ by only %.1f under %s", w.Kind, w.A, w.B, w.Difference, w.Deficiency)
+}
+
+// colorDeficiencies are the Machado et al. (2009) full-severity simulation
+// matrices, applied to linear RGB
+var colorDeficiencies = []struct {
+	name string
+	m    [3][3]float64
+}{
+	{"protanopia", [3][3]float64{
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	}},
+	{"deuteranopia", [3][3]float64{
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	}},
+	{"tritanopia", [3][3]float64{
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.147602},
+		{0.004733, 0.691367, 0.303900},
+	}},
+}
+
+// Validate checks that every pair of land colors and every pair of process
+// colors, including the defaults, stays distinguishable under simulated
+// protanopia, deuteranopia, and tritanopia. It returns one warning per
+// clashing pair, for the deficiency where they are closest. Two grays look
+// the same under every deficiency, so they are compared on lightness alone
+// and reported with Deficiency "all deficiencies".
+func (p *Palette) Validate() []Warning {
+	var warnings []Warning
+	warnings = append(warnings, validateColors("land", p.Lands, p.DefaultLand)...)
+	warnings = append(warnings, validateColors("process", p.Processes, p.DefaultProcess)...)
+	return warnings
+}
+
+func validateColors(kind string, colors map[string]color.RGBA, fallback color.RGBA) []Warning {
+	names := make([]string, 0, len(colors)+1)
+	for name := range colors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	names = append(names, "default")
+
+	lookup := func(name string) color.RGBA {
+		if c, ok := colors[name]; ok {
+			return c
+		}
+		return fallback
+	}
+
+	var warnings []Warning
+	for i, a := range names {
+		for _, b := range names[i+1:] {
+			ca, cb := lookup(a), lookup(b)
+			worst := Warning{Kind: kind, A: a, B: b, Difference: math.Inf(1)}
+			if isGray(ca) && isGray(cb) {
+				worst.Deficiency = "all deficiencies"
+				worst.Difference = math.Abs(lightness(ca) - lightness(cb))
+			} else {
+				for _, d := range colorDeficiencies {
+					diff := labDistance(simulateDeficiency(ca, d.m), simulateDeficiency(cb, d.m))
+					if diff < worst.Difference {
+						worst.Deficiency, worst.Difference = d.name, diff
+					}
+				}
+			}
+			if worst.Difference < MinColorDifference {
+				warnings = append(warnings, worst)
+			}
+		}
+	}
+	return warnings
+}
+
+func isGray(c color.RGBA) bool {
+	return c.R == c.G && c.G == c.B
+}
+
+// lightness is the CIE L* of c, which for a gray is its whole Lab distance
+// from another gray
+func lightness(c color.RGBA) float64 {
+	l := srgbToLinear(c.R)
+	return linearToLab([3]float64{l, l, l})[0]
+}
+
+// simulateDeficiency returns c as linear RGB as seen with the given
+// deficiency
+func simulateDeficiency(c color.RGBA, m [3][3]float64) [3]float64 {
+	in := [3]float64{srgbToLinear(c.R), srgbToLinear(c.G), srgbToLinear(c.B)}
+	var out [3]float64
+	for i := range out {
+		v := m[i][0]*in[0] + m[i][1]*in[1] + m[i][2]*in[2]
+		out[i] = max(0, min(1, v))
+	}
+	return out
+}
+
+func srgbToLinear(v uint8) float64 {
+	c := float64(v) / 255
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// labDistance is the CIE76 difference between two linear RGB colors
+func labDistance(a, b [3]float64) float64 {
+	la, lb := linearToLab(a), linearToLab(b)
+	return math.Sqrt((la[0]-lb[0])*(la[0]-lb[0]) + (la[1]-lb[1])*(la[1]-lb[1]) + (la[2]-lb[2])*(la[2]-lb[2]))
+}
+
+// linearToLab converts linear sRGB to CIE L*a*b* with a D65 white point
+func linearToLab(c [3]float64) [3]float64 {
+	x := (0.4124*c[0] + 0.3576*c[1] + 0.1805*c[2]) / 0.95047
+	y := 0.2126*c[0] + 0.7152*c[1] + 0.0722*c[2]
+	z := (0.0193*c[0] + 0.1192*c[1] + 0.9505*c[2]) / 1.08883
+
+	f := func(t float64) float64 {
+		if t > 0.008856 {
+			return math.Cbrt(t)
+		}
+		return 7.787*t + 16.0/116
+	}
+	fx, fy, fz := f(x), f(y), f(z)
+	return [3]float64{116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)}
+}
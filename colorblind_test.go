@@ -0,0 +1,40 @@
+package nimsforestsprites
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestValidateReportsDefaultPaletteClash(t *testing.T) {
+	// The default forest green is close to the gray fallback land color
+	for _, w := range DefaultPalette().Validate() {
+		if w.Kind == "land" && w.A == "forest" && w.B == "default" {
+			return
+		}
+	}
+	t.Fatal("expected a forest/default land warning for the default palette")
+}
+
+func TestValidateColorBlindSafePreset(t *testing.T) {
+	if warnings := ColorBlindSafePalette().Validate(); len(warnings) != 0 {
+		t.Fatalf("ColorBlindSafePalette has warnings: %v", warnings)
+	}
+}
+
+func TestValidateGrayscalePalette(t *testing.T) {
+	gray := func(v uint8) color.RGBA { return color.RGBA{v, v, v, 255} }
+	p := &Palette{
+		Lands:          map[string]color.RGBA{"forest": gray(80), "water": gray(82)},
+		Processes:      map[string]color.RGBA{"tree": gray(60)},
+		DefaultLand:    gray(200),
+		DefaultProcess: gray(220),
+	}
+
+	warnings := p.Validate()
+	if len(warnings) != 1 {
+		t.Fatalf("got warnings %v, want only forest/water", warnings)
+	}
+	if w := warnings[0]; w.Kind != "land" || w.A != "forest" || w.B != "water" {
+		t.Errorf("got warning %v, want forest/water lands", w)
+	}
+}
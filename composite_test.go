@@ -0,0 +1,31 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"testing"
+)
+
+func TestCompositeSideBySide(t *testing.T) {
+	left := &staticState{lands: []Land{{ID: "a", Type: "forest"}}}
+	right := &staticState{lands: []Land{{ID: "a", Type: "mana"}}}
+	c := NewComposite([]Viewport{
+		{Rect: image.Rect(0, 0, 200, 240), State: left},
+		{Rect: image.Rect(200, 0, 400, 240), State: right},
+		{Rect: image.Rect(350, 200, 900, 900), State: right}, // Clipped
+	})
+	img, err := c.Render(400, 240)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each state's tile, centered at (131, 131) in a full frame, lands at
+	// half the x in its viewport
+	forest := img.(*image.RGBA).RGBAAt(65, 131)
+	mana := img.(*image.RGBA).RGBAAt(200+65, 131)
+	if !(forest.G > forest.R && forest.G > forest.B) {
+		t.Errorf("left viewport tile is %v, want forest green", forest)
+	}
+	if !(mana.B > mana.G && mana.R > mana.G) {
+		t.Errorf("right viewport tile is %v, want mana purple", mana)
+	}
+}
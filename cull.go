@@ -0,0 +1,60 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"image/color"
+	"sort"
+	"strconv"
+)
+
+// overflowColor is the color of the "+N" indicator for culled processes
+var overflowColor = color.RGBA{230, 230, 230, 220}
+
+// overflowPixel is the size of one glyph pixel of the "+N" indicator
+const overflowPixel = 2
+
+// SetMaxVisibleProcesses caps how many processes are drawn per frame,
+// keeping frame time bounded for states with very many of them. Beyond
+// the cap, the processes with the highest Progress are drawn and a "+N"
+// indicator in the bottom-right corner counts the rest. n <= 0 means
+// unlimited, the default.
+func (r *Renderer) SetMaxVisibleProcesses(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxProcesses = max(0, n)
+}
+
+// cullProcesses keeps the limit processes with the highest Progress, in
+// their original order, and returns how many were left out. Ties keep the
+// earlier process. A limit of 0 keeps everything.
+func cullProcesses(processes []Process, limit int) ([]Process, int) {
+	if limit <= 0 || len(processes) <= limit {
+		return processes, 0
+	}
+
+	order := make([]int, len(processes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return processes[order[a]].Progress > processes[order[b]].Progress
+	})
+	keep := order[:limit]
+	sort.Ints(keep)
+
+	visible := make([]Process, limit)
+	for i, idx := range keep {
+		visible[i] = processes[idx]
+	}
+	return visible, len(processes) - limit
+}
+
+// overflowRects returns the glyph pixels of the "+N" indicator for hidden
+// culled processes, right-aligned to the bottom-right corner of a frame of
+// the given size
+func overflowRects(hidden, width, height int) []image.Rectangle {
+	text := "+" + strconv.Itoa(hidden)
+	x := width - 10 - textWidth(text, overflowPixel)
+	y := height - 10 - glyphHeight*overflowPixel
+	return textRects(text, x, y, overflowPixel)
+}
@@ -0,0 +1,46 @@
+package nimsforestsprites
+
+import (
+	"fmt"
+	"image"
+	"testing"
+)
+
+func TestCullingKeepsLimitAndDrawsCounter(t *testing.T) {
+	const width, height = 800, 500
+	r := newTestRenderer(t, Options{Width: width, Height: height})
+	r.SetMaxVisibleProcesses(10)
+
+	// One process per cell on a 10x5 grid, with rising Progress
+	var processes []Process
+	for i := 0; i < 50; i++ {
+		processes = append(processes, Process{
+			ID:       fmt.Sprintf("p%02d", i),
+			Type:     "tree",
+			Progress: float64(i) / 50,
+			X:        float64(i % 10),
+			Y:        float64(i / 10),
+		})
+	}
+	img := r.RenderAt(&staticState{processes: processes}, 0).(*image.RGBA)
+
+	// The bounce moves a sprite by at most 3 pixels, so the cell center is
+	// always covered
+	tree := DefaultPalette().ProcessColor("tree")
+	var drawn []string
+	for _, p := range processes {
+		if img.RGBAAt(132+64*int(p.X), 132+64*int(p.Y)) == tree {
+			drawn = append(drawn, p.ID)
+		}
+	}
+	if len(drawn) != 10 || drawn[0] != "p40" {
+		t.Fatalf("drew %v, want the 10 with the highest Progress, p40 to p49", drawn)
+	}
+
+	want := blendRGBA(DefaultPalette().Background, overflowColor)
+	for _, rect := range overflowRects(40, width, height) {
+		if got := img.RGBAAt(rect.Min.X, rect.Min.Y); got != want {
+			t.Fatalf("counter pixel at %v is %v, want %v", rect.Min, got, want)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"image/draw"
+	"slices"
+)
+
+// deltaScene records the sprites RenderDelta last drew, keyed by ID, so the
+// next call can tell which of them changed
+type deltaScene struct {
+	tileSize int
+	lands    map[string]Land
+	procs    map[string]Process
+	edges    map[[2]string]float64 // Flow keyed by the from and to land IDs
+	legend   image.Rectangle       // Panel of the legend, if enabled
+	trails   []image.Rectangle     // Bounds of the process trails, if enabled
+
+	// The frame before filters ran, kept only while filters or an output
+	// palette are set
+	raw *image.RGBA
+}
+
+func newDeltaScene(state State, tileSize int) *deltaScene {
+	s := &deltaScene{
+		tileSize: tileSize,
+		lands:    make(map[string]Land),
+		procs:    make(map[string]Process),
+		edges:    make(map[[2]string]float64),
+	}
+	if state == nil {
+		return s
+	}
+
+	lands := state.Lands()
+	for _, land := range lands {
+		s.lands[land.ID] = land
+	}
+	for _, proc := range state.Processes() {
+		s.procs[proc.ID] = proc
+	}
+	for _, seg := range resolveEdges(state, lands) {
+		s.edges[[2]string{seg.from.ID, seg.to.ID}] = seg.flow
+	}
+	return s
+}
+
+// RenderDelta renders the next frame for state by copying prev and
+// redrawing only the regions whose lands, processes, or edges changed
+// since the previous RenderDelta call. The returned rectangles can drive
+// delta encoding: only those regions need to be sent to a consumer
+// already holding prev.
+//
+// Animation is not part of the diff, so outside the dirty rectangles the
+// frame keeps prev's pulse and bounce. Style changes such as SetPalette
+// are not tracked either. If prev is nil or a different size, or on the
+// first call, the whole frame is redrawn and reported dirty.
+//
+// Filters and the output palette run over the whole frame, as they do for
+// Render, with the dirty regions redrawn into an unfiltered copy of the
+// previous frame kept for this. Dirty rectangles are grown by the reach of
+// Bloom, the widest built-in filter; a custom filter that spreads pixels
+// further may change pixels outside them.
+func (r *Renderer) RenderDelta(prev *image.RGBA, state State) (*image.RGBA, []image.Rectangle) {
+	r.mu.Lock()
+	r.setStateLocked(state)
+	r.tick++
+	r.advanceLocked(r.frameDuration())
+	phase := animationPhase(r.elapsed)
+	state = r.frameStateLocked()
+	style := r.styleLocked()
+	last := r.deltaScene
+	closed := r.closed
+	r.mu.Unlock()
+
+	if closed {
+		return nil, nil
+	}
+
+	tileSize := int(64 * style.scale)
+	filtered := len(style.filters) > 0 || style.quantizer != nil
+	scene := newDeltaScene(state, tileSize)
+	if style.legend {
+		scene.legend = legendBox(state, r.opts.Width, r.opts.Height)
+	}
+	if state != nil {
+		scene.trails = trailRects(state, tileSize, landLifts(state.Lands(), tileSize))
+	}
+	defer func() {
+		r.mu.Lock()
+		r.deltaScene = scene
+		r.mu.Unlock()
+	}()
+
+	bounds := image.Rect(0, 0, r.opts.Width, r.opts.Height)
+	raw := image.NewRGBA(bounds)
+	var dirty []image.Rectangle
+	// Culling picks processes and draws a counter across the whole frame
+	limit := style.maxProcesses
+	culled := limit > 0 && last != nil && max(len(scene.procs), len(last.procs)) > limit
+	if prev == nil || prev.Bounds() != bounds || last == nil || last.tileSize != tileSize || culled || filtered && last.raw == nil {
+		r.drawSceneSW(raw, state, phase, style)
+		dirty = []image.Rectangle{bounds}
+	} else {
+		base := prev
+		if filtered {
+			base = last.raw
+		}
+		draw.Draw(raw, bounds, base, bounds.Min, draw.Src)
+		dirty = last.dirtyRects(scene, bounds)
+		if style.legend {
+			// The legend lists the types in the state; redraw its old and
+			// new panel rather than diffing the lists
+			for _, rect := range []image.Rectangle{last.legend, scene.legend} {
+				if !rect.Empty() {
+					dirty = append(dirty, rect)
+				}
+			}
+			dirty = mergeRects(dirty)
+		}
+		// Trails stretch over several cells and fade every frame
+		if len(last.trails) > 0 || len(scene.trails) > 0 {
+			for _, rects := range [][]image.Rectangle{last.trails, scene.trails} {
+				for _, rect := range rects {
+					if rect = rect.Intersect(bounds); !rect.Empty() {
+						dirty = append(dirty, rect)
+					}
+				}
+			}
+			dirty = mergeRects(dirty)
+		}
+		for _, rect := range dirty {
+			r.drawSceneSW(raw.SubImage(rect).(*image.RGBA), state, phase, style)
+		}
+	}
+	if !filtered {
+		return raw, dirty
+	}
+
+	scene.raw = raw
+	next := image.NewRGBA(bounds)
+	copy(next.Pix, raw.Pix)
+	finishFrame(next, style)
+	for i, rect := range dirty {
+		dirty[i] = rect.Inset(-bloomRadius).Intersect(bounds)
+	}
+	return next, mergeRects(dirty)
+}
+
+// dirtyRects returns the frame regions that differ between s and next:
+// where changed sprites were and where they are now. Overlapping regions
+// are merged.
+func (s *deltaScene) dirtyRects(next *deltaScene, bounds image.Rectangle) []image.Rectangle {
+	var rects []image.Rectangle
+	add := func(rect image.Rectangle) {
+		if rect = rect.Intersect(bounds); !rect.Empty() {
+			rects = append(rects, rect)
+		}
+	}
+
+	// A moved land also moves its processes and edges
+	moved := make(map[string]bool)
+	for id, land := range s.lands {
+		if n, ok := next.lands[id]; !ok || n != land {
+			moved[id] = true
+			add(s.landRect(land))
+		}
+	}
+	for id, land := range next.lands {
+		if o, ok := s.lands[id]; !ok || o != land {
+			moved[id] = true
+			add(next.landRect(land))
+		}
+	}
+
+	for id, proc := range s.procs {
+		if n, ok := next.procs[id]; !ok || n != proc || moved[proc.LandID] {
+			add(s.procRect(proc))
+		}
+	}
+	for id, proc := range next.procs {
+		if o, ok := s.procs[id]; !ok || o != proc || moved[proc.LandID] {
+			add(next.procRect(proc))
+		}
+	}
+
+	for key, flow := range s.edges {
+		if n, ok := next.edges[key]; !ok || n != flow || moved[key[0]] || moved[key[1]] {
+			add(s.edgeRect(key))
+		}
+	}
+	for key, flow := range next.edges {
+		if o, ok := s.edges[key]; !ok || o != flow || moved[key[0]] || moved[key[1]] {
+			add(next.edgeRect(key))
+		}
+	}
+
+	return mergeRects(rects)
+}
+
+// landCenter returns the pixel center of a land's tile top
+func (s *deltaScene) landCenter(land Land) image.Point {
+	half := (s.tileSize - 2) / 2
+	return image.Pt(100+int(land.X*float64(s.tileSize))+half, 100+int(land.Y*float64(s.tileSize))-landLift(land, s.tileSize)+half)
+}
+
+// landRect covers a land's tile, its raised side, and its highlight frame
+func (s *deltaScene) landRect(land Land) image.Rectangle {
+	lift := landLift(land, s.tileSize)
+	x := 100 + int(land.X*float64(s.tileSize))
+	y := 100 + int(land.Y*float64(s.tileSize)) - lift
+	return image.Rect(x, y, x+s.tileSize, y+s.tileSize+lift).Inset(-highlightWidth)
+}
+
+// procRect covers every spot a process can be drawn in its cell, whatever
+// its fan-out slot and bounce, plus the line attaching it to its land
+func (s *deltaScene) procRect(proc Process) image.Rectangle {
+	land, ok := s.lands[proc.LandID]
+	lift := 0
+	if ok {
+		lift = landLift(land, s.tileSize)
+	}
+	cx := 100 + int(proc.X*float64(s.tileSize)) + s.tileSize/2
+	cy := 100 + int(proc.Y*float64(s.tileSize)) + s.tileSize/2 - lift
+	pad := s.tileSize/4 + 3 + 13 + 1 // Fan-out ring, bounce, highlight glow
+	rect := image.Rect(cx-pad, cy-pad, cx+pad, cy+pad)
+	if ok {
+		c := s.landCenter(land)
+		rect = rect.Union(image.Rect(c.X-1, c.Y-1, c.X+2, c.Y+2))
+	}
+	return rect
+}
+
+// edgeRect covers the line between two lands and its flow dots
+func (s *deltaScene) edgeRect(key [2]string) image.Rectangle {
+	a, b := s.landCenter(s.lands[key[0]]), s.landCenter(s.lands[key[1]])
+	return image.Rectangle{a, b}.Canon().Inset(-4)
+}
+
+// mergeRects unions overlapping rectangles until none overlap, returning
+// them ordered top to bottom, left to right
+func mergeRects(rects []image.Rectangle) []image.Rectangle {
+	byPosition := func(a, b image.Rectangle) int {
+		if a.Min.Y != b.Min.Y {
+			return a.Min.Y - b.Min.Y
+		}
+		return a.Min.X - b.Min.X
+	}
+	slices.SortFunc(rects, byPosition)
+
+	for merged := true; merged; {
+		merged = false
+		for i := 0; i < len(rects); i++ {
+			for j := i + 1; j < len(rects); j++ {
+				if rects[i].Overlaps(rects[j]) {
+					rects[i] = rects[i].Union(rects[j])
+					rects = slices.Delete(rects, j, j+1)
+					merged = true
+					j = i
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(rects, byPosition)
+	return rects
+}
@@ -0,0 +1,57 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestRenderDeltaFiltersMatchRenderAt(t *testing.T) {
+	r := newTestRenderer(t, Options{Width: 400, Height: 300, RenderWorkers: 1})
+	r.AddFilter(Scanlines)
+	r.AddFilter(Bloom)
+	lands := []Land{
+		{ID: "a", X: 0, Y: 0, Type: "forest"},
+		{ID: "b", X: 1, Y: 0, Type: "mana"},
+		{ID: "c", X: 2, Y: 1, Type: "water"},
+	}
+	at := func(x float64) State {
+		return &staticState{lands: lands, processes: []Process{
+			{ID: "p", LandID: "a", Type: "nim", X: x},
+			{ID: "q", LandID: "c", Type: "tree", X: 2, Y: 1},
+		}}
+	}
+
+	// Resetting the tick keeps every frame at the same animation phase, so
+	// only the moved process differs between them
+	r.ResetTick()
+	first, _ := r.RenderDelta(nil, at(0))
+	r.ResetTick()
+	next, dirty := r.RenderDelta(first, at(0.7))
+	want := r.RenderAt(at(0.7), 1).(*image.RGBA)
+
+	if len(dirty) == 0 || dirty[0] == next.Bounds() {
+		t.Fatalf("dirty = %v, want a partial redraw", dirty)
+	}
+	if !bytes.Equal(next.Pix, want.Pix) {
+		t.Error("RenderDelta with Scanlines and Bloom differs from RenderAt")
+	}
+
+	// Every pixel that changed must be reported dirty
+	b := next.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			i := next.PixOffset(x, y)
+			if bytes.Equal(next.Pix[i:i+4], first.Pix[i:i+4]) {
+				continue
+			}
+			covered := false
+			for _, rect := range dirty {
+				covered = covered || image.Pt(x, y).In(rect)
+			}
+			if !covered {
+				t.Fatalf("pixel (%d, %d) changed outside dirty rects %v", x, y, dirty)
+			}
+		}
+	}
+}
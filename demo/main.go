@@ -22,6 +22,8 @@ func main() {
 	fps := flag.Int("fps", 30, "Frames per second")
 	duration := flag.Duration("duration", 5*time.Second, "Demo duration")
 	outputDir := flag.String("output", "", "Output directory for frames (if empty, no files saved)")
+	gifPath := flag.String("gif", "", "Write an animated GIF of the run to this file")
+	seed := flag.Int64("seed", 0, "Seed for the mock state (0 = random)")
 	flag.Parse()
 
 	fmt.Println("nimsforestsprites demo")
@@ -43,6 +45,9 @@ func main() {
 
 	// Create mock state
 	mockState := sprites.NewMockState()
+	if *seed != 0 {
+		mockState = sprites.NewMockStateSeed(*seed)
+	}
 	renderer.Update(mockState)
 
 	// Create output directory if specified
@@ -85,6 +90,23 @@ func main() {
 		}
 	}()
 
+	// Encode an animated GIF alongside the frame loop if requested
+	var gifFrames chan image.Image
+	gifDone := make(chan error, 1)
+	if *gifPath != "" {
+		gifFile, err := os.Create(*gifPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create GIF file: %v\n", err)
+			os.Exit(1)
+		}
+		defer gifFile.Close()
+
+		gifFrames = make(chan image.Image, 2)
+		go func() {
+			gifDone <- sprites.EncodeGIF(context.Background(), gifFrames, gifFile, sprites.GIFOptions{FrameRate: *fps})
+		}()
+	}
+
 	// Consume frames
 	frameCount := 0
 	startTime := time.Now()
@@ -102,6 +124,10 @@ func main() {
 			}
 		}
 
+		if gifFrames != nil {
+			gifFrames <- frame
+		}
+
 		// Print progress every 30 frames
 		if frameCount%30 == 0 {
 			elapsed := time.Since(startTime)
@@ -121,6 +147,15 @@ func main() {
 	if *outputDir != "" {
 		fmt.Printf("Frames saved to: %s\n", *outputDir)
 	}
+
+	if gifFrames != nil {
+		close(gifFrames)
+		if err := <-gifDone; err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write GIF: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("GIF saved to: %s\n", *gifPath)
+	}
 }
 
 func saveFrame(filename string, img image.Image) error {
@@ -0,0 +1,43 @@
+package nimsforestsprites
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"image"
+	"image/color"
+)
+
+// FrameDigest returns the hex SHA-256 of img's width and height followed
+// by its pixels as 8-bit premultiplied RGBA in row-major order. It depends
+// only on the size and pixel values, not on the image type, stride, or
+// origin, so together with RenderAt it gives a compact golden value for
+// regression checks.
+func FrameDigest(img image.Image) string {
+	h := sha256.New()
+	b := img.Bounds()
+
+	// Without the size, a 2x1 and a 1x2 image of the same pixels collide
+	var size [8]byte
+	binary.BigEndian.PutUint32(size[:4], uint32(b.Dx()))
+	binary.BigEndian.PutUint32(size[4:], uint32(b.Dy()))
+	h.Write(size[:])
+
+	if rgba, ok := img.(*image.RGBA); ok {
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			h.Write(rgba.Pix[rgba.PixOffset(b.Min.X, y):rgba.PixOffset(b.Max.X, y)])
+		}
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	row := make([]byte, 4*b.Dx())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+			i := 4 * (x - b.Min.X)
+			row[i], row[i+1], row[i+2], row[i+3] = c.R, c.G, c.B, c.A
+		}
+		h.Write(row)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
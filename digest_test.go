@@ -0,0 +1,56 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"testing"
+)
+
+// goldenDigest is FrameDigest of goldenFrame. Update it only for an
+// intended change to the software renderer's output.
+const goldenDigest = "223f3e5141374a23d13aacb28a166445ab8861d9fdedd138f8e2f845b1645f4c"
+
+func goldenFrame(t *testing.T) image.Image {
+	t.Helper()
+	r, err := NewHeadless(Options{Width: 320, Height: 240, FrameRate: 30, RenderWorkers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	return r.RenderAt(NewMockStateSeed(42), 10)
+}
+
+func TestFrameDigestGolden(t *testing.T) {
+	if got := FrameDigest(goldenFrame(t)); got != goldenDigest {
+		t.Fatalf("FrameDigest = %s, want %s", got, goldenDigest)
+	}
+}
+
+func TestFrameDigestIgnoresStride(t *testing.T) {
+	src := goldenFrame(t).(*image.RGBA)
+	b := src.Bounds()
+
+	// Same pixels, but each row padded with junk and offset in the plane
+	padded := &image.RGBA{
+		Pix:    make([]uint8, (4*b.Dx()+16)*b.Dy()),
+		Stride: 4*b.Dx() + 16,
+		Rect:   b.Add(image.Pt(5, 7)),
+	}
+	for i := range padded.Pix {
+		padded.Pix[i] = 0xAB
+	}
+	for y := 0; y < b.Dy(); y++ {
+		copy(padded.Pix[y*padded.Stride:], src.Pix[y*src.Stride:y*src.Stride+4*b.Dx()])
+	}
+
+	if FrameDigest(padded) != FrameDigest(src) {
+		t.Fatal("digest depends on stride or origin")
+	}
+}
+
+func TestFrameDigestIncludesSize(t *testing.T) {
+	wide := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	tall := image.NewRGBA(image.Rect(0, 0, 1, 2))
+	if FrameDigest(wide) == FrameDigest(tall) {
+		t.Fatal("2x1 and 1x2 images with the same pixels share a digest")
+	}
+}
@@ -0,0 +1,151 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// Edge represents a connection between two lands
+type Edge struct {
+	FromLandID string
+	ToLandID   string
+	Flow       float64 // Activity from FromLandID to ToLandID, shown as moving dots (0 = none)
+}
+
+// EdgeState is implemented by states that also expose connections between
+// lands. The renderer detects it with a type assertion, so a plain State
+// renders exactly as before.
+type EdgeState interface {
+	State
+	// Edges returns all connections to render
+	Edges() []Edge
+}
+
+// edgeColor is the base color for edges, drawn beneath the land tiles
+var edgeColor = color.RGBA{140, 160, 150, 255}
+
+// flowDotColor is the color of the dots animating Edge.Flow
+var flowDotColor = color.RGBA{220, 240, 230, 255}
+
+// maxFlowDots caps the dots on one edge however high its Flow
+const maxFlowDots = 8
+
+// edgeSegment is an edge resolved to the lands at either end
+type edgeSegment struct {
+	from, to Land
+	flow     float64
+}
+
+// resolveEdges returns the edges of state whose lands both exist, skipping
+// any that reference unknown land IDs.
+func resolveEdges(state State, lands []Land) []edgeSegment {
+	es, ok := state.(EdgeState)
+	if !ok {
+		return nil
+	}
+
+	edges := es.Edges()
+	if len(edges) == 0 {
+		return nil
+	}
+
+	byID := landsByID(lands)
+	segments := make([]edgeSegment, 0, len(edges))
+	for _, e := range edges {
+		from, ok := byID[e.FromLandID]
+		if !ok {
+			continue
+		}
+		to, ok := byID[e.ToLandID]
+		if !ok {
+			continue
+		}
+		segments = append(segments, edgeSegment{from: from, to: to, flow: e.Flow})
+	}
+	return segments
+}
+
+// fade returns the opacity of the segment. Longer edges are drawn fainter so
+// local structure stands out.
+func (s edgeSegment) fade() float64 {
+	dist := math.Hypot(s.to.X-s.from.X, s.to.Y-s.from.Y)
+	return math.Max(0.25, 1/(1+dist*0.3))
+}
+
+// flowDots returns where the flow dots are at the given animation phase,
+// as fractions of the way from the source land to the destination. Higher
+// flow means more dots moving faster; an edge without flow has none.
+func (s edgeSegment) flowDots(phase float64) []float64 {
+	dist := math.Hypot(s.to.X-s.from.X, s.to.Y-s.from.Y)
+	if s.flow <= 0 || dist == 0 {
+		return nil
+	}
+
+	n := min(maxFlowDots, int(math.Ceil(s.flow*2)))
+	speed := min(4, 0.5+s.flow/2) // Tiles per second
+	travelled := phase / animationRate * speed / dist
+
+	dots := make([]float64, n)
+	for i := range dots {
+		_, dots[i] = math.Modf(travelled + float64(i)/float64(n))
+	}
+	return dots
+}
+
+// drawLineSW draws a line with a square pen of the given width
+func drawLineSW(img *image.RGBA, x0, y0, x1, y1, width int, c color.RGBA, maxW, maxH int) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	offset := width / 2
+
+	// Neighboring pen squares overlap, so a translucent line would blend
+	// some pixels twice. Collect them first and blend each once.
+	var covered map[image.Point]bool
+	if c.A < 255 {
+		covered = make(map[image.Point]bool)
+	}
+
+	err := dx + dy
+	for {
+		if covered != nil {
+			for y := y0 - offset; y < y0-offset+width; y++ {
+				for x := x0 - offset; x < x0-offset+width; x++ {
+					covered[image.Pt(x, y)] = true
+				}
+			}
+		} else {
+			fillRectSW(img, x0-offset, y0-offset, width, width, c, maxW, maxH)
+		}
+		if x0 == x1 && y0 == y1 {
+			break
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+
+	for p := range covered {
+		fillRectSW(img, p.X, p.Y, 1, 1, c, maxW, maxH)
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
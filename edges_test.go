@@ -0,0 +1,86 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"testing"
+	"time"
+)
+
+// edgeTestState adds fixed edges to a staticState
+type edgeTestState struct {
+	*staticState
+	edges []Edge
+}
+
+func (s edgeTestState) Edges() []Edge { return s.edges }
+
+func TestEdgeDrawnBetweenLandCenters(t *testing.T) {
+	r := newTestRenderer(t, Options{Width: 480, Height: 240})
+	lands := []Land{
+		{ID: "a", X: 0, Y: 0, Type: "forest"},
+		{ID: "b", X: 3, Y: 0, Type: "forest"},
+	}
+	plain := &staticState{lands: lands}
+	linked := edgeTestState{plain, []Edge{{FromLandID: "a", ToLandID: "b"}}}
+
+	without := r.RenderAt(plain, 0).(*image.RGBA)
+	with := r.RenderAt(linked, 0).(*image.RGBA)
+
+	// Tile centers are at (131, 131) and (323, 131); the gap between the
+	// tiles only shows the line
+	for _, x := range []int{180, 227, 270} {
+		if with.RGBAAt(x, 131) == without.RGBAAt(x, 131) {
+			t.Errorf("no line drawn at (%d, 131)", x)
+		}
+	}
+	// Off the line the gap is untouched
+	if with.RGBAAt(227, 150) != without.RGBAAt(227, 150) {
+		t.Error("edge drew outside the line between the centers")
+	}
+}
+
+func TestEdgeToUnknownLandSkipped(t *testing.T) {
+	r := newTestRenderer(t, Options{})
+	plain := &staticState{lands: []Land{{ID: "a", Type: "forest"}}}
+	dangling := edgeTestState{plain, []Edge{{FromLandID: "a", ToLandID: "missing"}}}
+
+	if FrameDigest(r.RenderAt(dangling, 0)) != FrameDigest(r.RenderAt(plain, 0)) {
+		t.Fatal("an edge to an unknown land changed the frame")
+	}
+}
+
+func TestFlowDotsTravelAlongEdge(t *testing.T) {
+	lands := []Land{
+		{ID: "a", X: 0, Y: 0, Type: "forest"},
+		{ID: "b", X: 3, Y: 0, Type: "forest"},
+	}
+	state := edgeTestState{&staticState{lands: lands}, []Edge{{FromLandID: "a", ToLandID: "b", Flow: 1}}}
+	seg := resolveEdges(state, lands)[0]
+
+	// Between two frames a dot moves on toward the destination
+	phase := animationPhase(time.Second / 30)
+	before, after := seg.flowDots(0)[0], seg.flowDots(phase)[0]
+	if !(after > before) {
+		t.Errorf("first dot went from %v to %v along the edge, want it to progress", before, after)
+	}
+
+	seg.flow = 0
+	if dots := seg.flowDots(phase); len(dots) != 0 {
+		t.Errorf("Flow 0 drew %d dots", len(dots))
+	}
+	seg.flow = 1000
+	if dots := seg.flowDots(phase); len(dots) != maxFlowDots {
+		t.Errorf("Flow 1000 drew %d dots, want the cap of %d", len(dots), maxFlowDots)
+	}
+
+	// The dot is drawn where flowDots puts it, on the line at y = 131
+	r := newTestRenderer(t, Options{Width: 480, Height: 240})
+	without := r.RenderAt(state, 10).(*image.RGBA)
+	r.SetFlowEnabled(true)
+	with := r.RenderAt(state, 10).(*image.RGBA)
+	seg.flow = 1
+	x := 131 + int(seg.flowDots(animationPhase(10 * time.Second / 30))[0]*3*64)
+	if with.RGBAAt(x, 131) == without.RGBAAt(x, 131) {
+		t.Errorf("no flow dot drawn at (%d, 131)", x)
+	}
+}
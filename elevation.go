@@ -0,0 +1,35 @@
+package nimsforestsprites
+
+import "image"
+
+// elevationShade darkens a raised land's side face relative to its top
+const elevationShade = -0.35
+
+// landLift returns how many pixels a land's tile is raised for its
+// Elevation. One unit of elevation is a quarter tile; negative elevations
+// draw flat.
+func landLift(land Land, tileSize int) int {
+	return max(0, int(land.Elevation*float64(tileSize)/4))
+}
+
+// landLifts maps the IDs of raised lands to their lift in pixels, so
+// edges and processes can sit on top of them. It is nil when every land
+// is flat.
+func landLifts(lands []Land, tileSize int) map[string]int {
+	var lifts map[string]int
+	for _, land := range lands {
+		if lift := landLift(land, tileSize); lift > 0 {
+			if lifts == nil {
+				lifts = make(map[string]int)
+			}
+			lifts[land.ID] = lift
+		}
+	}
+	return lifts
+}
+
+// landSide returns the side face below a tile of the given size whose top
+// was raised to (x, y) by lift pixels. It is empty for a flat land.
+func landSide(x, y, size, lift int) image.Rectangle {
+	return image.Rect(x, y+size, x+size, y+size+lift)
+}
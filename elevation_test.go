@@ -0,0 +1,32 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"testing"
+)
+
+func TestElevatedLandDrawnHigher(t *testing.T) {
+	r := newTestRenderer(t, Options{})
+	bg := DefaultPalette().Background
+
+	// topRow is the first row down the tile's middle column that isn't
+	// background
+	topRow := func(elevation float64) int {
+		state := &staticState{lands: []Land{{ID: "a", Type: "forest", Elevation: elevation}}}
+		img := r.RenderAt(state, 0).(*image.RGBA)
+		for y := 0; y < img.Bounds().Dy(); y++ {
+			if img.RGBAAt(131, y) != bg {
+				return y
+			}
+		}
+		return -1
+	}
+
+	// One unit of elevation lifts the tile by a quarter of its 64 pixels
+	if got := topRow(0); got != 100 {
+		t.Errorf("flat land's top at y = %d, want 100", got)
+	}
+	if got := topRow(1); got != 84 {
+		t.Errorf("land at elevation 1 has its top at y = %d, want 84", got)
+	}
+}
@@ -0,0 +1,150 @@
+package nimsforestsprites
+
+import "image"
+
+// Filter post-processes a finished frame in place. Filters see
+// premultiplied RGBA and must not change the image's bounds.
+type Filter func(dst *image.RGBA)
+
+// AddFilter appends f to the filters run, in order, on every frame after
+// the scene is drawn. GPU frames are filtered once they are captured.
+func (r *Renderer) AddFilter(f Filter) {
+	if f == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Copied so frames already being filtered keep their list
+	filters := make([]Filter, len(r.filters), len(r.filters)+1)
+	copy(filters, r.filters)
+	r.filters = append(filters, f)
+}
+
+// ClearFilters removes all filters
+func (r *Renderer) ClearFilters() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.filters = nil
+}
+
+// finishFrame runs the style's filters over a drawn frame, in order, and
+// then snaps it to the output palette
+func finishFrame(img *image.RGBA, style drawStyle) {
+	for _, f := range style.filters {
+		f(img)
+	}
+	if style.quantizer != nil {
+		style.quantizer.quantize(img)
+	}
+}
+
+// Grayscale replaces each pixel with its Rec. 601 luma
+func Grayscale(dst *image.RGBA) {
+	eachPixel(dst, func(p []uint8) {
+		y := luma(p)
+		p[0], p[1], p[2] = y, y, y
+	})
+}
+
+// Sepia tints the frame in warm brown tones
+func Sepia(dst *image.RGBA) {
+	eachPixel(dst, func(p []uint8) {
+		r, g, b := float64(p[0]), float64(p[1]), float64(p[2])
+		a := float64(p[3]) // Premultiplied channels can't exceed alpha
+		p[0] = uint8(min(a, 0.393*r+0.769*g+0.189*b))
+		p[1] = uint8(min(a, 0.349*r+0.686*g+0.168*b))
+		p[2] = uint8(min(a, 0.272*r+0.534*g+0.131*b))
+	})
+}
+
+// Scanlines darkens every odd row, like an old CRT. Rows are numbered in
+// dst's own coordinates, so a sub-image gets the same rows as its frame.
+func Scanlines(dst *image.RGBA) {
+	b := dst.Bounds()
+	for y := b.Min.Y | 1; y < b.Max.Y; y += 2 {
+		row := dst.Pix[dst.PixOffset(b.Min.X, y):dst.PixOffset(b.Max.X, y)]
+		for i := 0; i < len(row); i += 4 {
+			for c := i; c < i+3; c++ {
+				row[c] = uint8(int(row[c]) * 5 / 8)
+			}
+		}
+	}
+}
+
+// bloomThreshold is the luma above which Bloom makes a pixel glow
+const bloomThreshold = 160
+
+// bloomRadius is how far, in pixels, the glow spreads
+const bloomRadius = 4
+
+// Bloom makes bright areas glow onto their surroundings
+func Bloom(dst *image.RGBA) {
+	b := dst.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return
+	}
+
+	// Keep only the bright pixels, then blur them
+	glow := make([][3]float64, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			p := dst.Pix[dst.PixOffset(b.Min.X+x, b.Min.Y+y):]
+			if luma(p) > bloomThreshold {
+				glow[y*w+x] = [3]float64{float64(p[0]), float64(p[1]), float64(p[2])}
+			}
+		}
+	}
+	boxBlur(glow, w, h, 1, w)
+	boxBlur(glow, h, w, w, 1)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			p := dst.Pix[dst.PixOffset(b.Min.X+x, b.Min.Y+y):]
+			g := glow[y*w+x]
+			for c := 0; c < 3; c++ {
+				p[c] = uint8(min(float64(p[3]), float64(p[c])+g[c]))
+			}
+		}
+	}
+}
+
+// boxBlur blurs buf in place along one axis: lines runs of n values, each
+// value step apart and each run stride after the previous one
+func boxBlur(buf [][3]float64, n, lines, step, stride int) {
+	line := make([][3]float64, n)
+	for l := 0; l < lines; l++ {
+		for i := range line {
+			line[i] = buf[l*stride+i*step]
+		}
+		for i := range line {
+			var sum [3]float64
+			for j := max(0, i-bloomRadius); j <= min(n-1, i+bloomRadius); j++ {
+				for c := range sum {
+					sum[c] += line[j][c]
+				}
+			}
+			for c := range sum {
+				sum[c] /= 2*bloomRadius + 1
+			}
+			buf[l*stride+i*step] = sum
+		}
+	}
+}
+
+// eachPixel calls fn with the four channels of every pixel in img
+func eachPixel(img *image.RGBA, fn func(p []uint8)) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := img.Pix[img.PixOffset(b.Min.X, y):img.PixOffset(b.Max.X, y)]
+		for i := 0; i < len(row); i += 4 {
+			fn(row[i : i+4 : i+4])
+		}
+	}
+}
+
+// luma is the Rec. 601 brightness of an RGBA pixel
+func luma(p []uint8) uint8 {
+	return uint8((299*int(p[0]) + 587*int(p[1]) + 114*int(p[2]) + 500) / 1000)
+}
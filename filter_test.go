@@ -0,0 +1,21 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"testing"
+)
+
+func TestGrayscaleFilter(t *testing.T) {
+	r := newTestRenderer(t, Options{})
+	r.AddFilter(Grayscale)
+
+	img := r.RenderAt(NewMockStateSeed(2), 5).(*image.RGBA)
+	for i := 0; i < len(img.Pix); i += 4 {
+		if p := img.Pix[i : i+3]; p[0] != p[1] || p[1] != p[2] {
+			t.Fatalf("pixel %d is %v, want R == G == B", i/4, p)
+		}
+	}
+	if img.Bounds() != image.Rect(0, 0, 320, 240) {
+		t.Fatalf("filter changed the bounds to %v", img.Bounds())
+	}
+}
@@ -0,0 +1,150 @@
+package nimsforestsprites
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+	"sort"
+)
+
+// GIFOptions configures EncodeGIF
+type GIFOptions struct {
+	FrameRate int // Frame rate the frames were rendered at (default 30)
+	MaxFrames int // Stop after this many frames (0 = until the channel closes)
+
+	// Palette replaces the palette built from the first frame, e.g. with
+	// Renderer.OutputPalette so the GIF keeps quantized frames exact. At
+	// most 256 colors.
+	Palette color.Palette
+}
+
+// EncodeGIF consumes frames (typically from Renderer.Frames) and writes them
+// to w as a looping animated GIF. Unless GIFOptions.Palette is set, the
+// palette is built once per run from the most common colors of the first
+// frame, so the flat background and tile colors are kept exact. Encoding
+// stops when the channel closes, MaxFrames is reached, or ctx is
+// cancelled; the frames collected so far are written in every case.
+func EncodeGIF(ctx context.Context, frames <-chan image.Image, w io.Writer, opts GIFOptions) error {
+	if opts.FrameRate <= 0 {
+		opts.FrameRate = 30
+	}
+	// GIF delays are in hundredths of a second
+	delay := max(1, (100+opts.FrameRate/2)/opts.FrameRate)
+
+	if len(opts.Palette) > 256 {
+		return fmt.Errorf("GIF palette has %d colors, more than 256", len(opts.Palette))
+	}
+
+	anim := &gif.GIF{}
+	var q *paletteQuantizer
+	if len(opts.Palette) > 0 {
+		q = newPaletteQuantizer(opts.Palette)
+	}
+
+collect:
+	for opts.MaxFrames == 0 || len(anim.Image) < opts.MaxFrames {
+		select {
+		case <-ctx.Done():
+			break collect
+		case frame, ok := <-frames:
+			if !ok {
+				break collect
+			}
+			if q == nil {
+				q = newPaletteQuantizer(adaptivePalette(frame, 256))
+			}
+			anim.Image = append(anim.Image, q.quantize(frame))
+			anim.Delay = append(anim.Delay, delay)
+		}
+	}
+
+	if len(anim.Image) == 0 {
+		return errors.New("no frames to encode")
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// adaptivePalette returns up to n of the most frequent opaque colors in img.
+func adaptivePalette(img image.Image, n int) color.Palette {
+	counts := make(map[color.RGBA]int)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			counts[opaqueRGBA(img, x, y)]++
+		}
+	}
+
+	colors := make([]color.RGBA, 0, len(counts))
+	for c := range counts {
+		colors = append(colors, c)
+	}
+	sort.Slice(colors, func(i, j int) bool {
+		ci, cj := counts[colors[i]], counts[colors[j]]
+		if ci != cj {
+			return ci > cj
+		}
+		// Deterministic order for equally common colors
+		return rgbaKey(colors[i]) < rgbaKey(colors[j])
+	})
+	if len(colors) > n {
+		colors = colors[:n]
+	}
+
+	palette := make(color.Palette, len(colors))
+	for i, c := range colors {
+		palette[i] = c
+	}
+	return palette
+}
+
+// paletteQuantizer maps pixels to a fixed palette, caching lookups since
+// rendered frames reuse a handful of colors.
+type paletteQuantizer struct {
+	palette color.Palette
+	cache   map[color.RGBA]uint8
+}
+
+func newPaletteQuantizer(p color.Palette) *paletteQuantizer {
+	return &paletteQuantizer{palette: p, cache: make(map[color.RGBA]uint8)}
+}
+
+func (q *paletteQuantizer) index(c color.RGBA) uint8 {
+	idx, ok := q.cache[c]
+	if !ok {
+		idx = uint8(q.palette.Index(c))
+		q.cache[c] = idx
+	}
+	return idx
+}
+
+func (q *paletteQuantizer) quantize(img image.Image) *image.Paletted {
+	b := img.Bounds()
+	out := image.NewPaletted(b, q.palette)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		row := out.PixOffset(b.Min.X, y)
+		for x := b.Min.X; x < b.Max.X; x++ {
+			out.Pix[row+x-b.Min.X] = q.index(opaqueRGBA(img, x, y))
+		}
+	}
+	return out
+}
+
+// opaqueRGBA reads a pixel as an opaque color. Frames are drawn over an
+// opaque background, so the alpha channel carries no useful information for
+// formats without translucency.
+func opaqueRGBA(img image.Image, x, y int) color.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		i := rgba.PixOffset(x, y)
+		return color.RGBA{rgba.Pix[i], rgba.Pix[i+1], rgba.Pix[i+2], 255}
+	}
+	r, g, b, _ := img.At(x, y).RGBA()
+	return color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), 255}
+}
+
+func rgbaKey(c color.RGBA) uint32 {
+	return uint32(c.R)<<16 | uint32(c.G)<<8 | uint32(c.B)
+}
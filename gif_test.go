@@ -0,0 +1,44 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/gif"
+	"testing"
+)
+
+func TestEncodeGIFFrameCountAndDelay(t *testing.T) {
+	r, err := NewHeadless(Options{Width: 320, Height: 240, FrameRate: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	state := NewMockStateSeed(1)
+	frames := make(chan image.Image, 3)
+	for tick := 0; tick < 3; tick++ {
+		frames <- r.RenderAt(state, tick)
+		state.Randomize()
+	}
+	close(frames)
+
+	var buf bytes.Buffer
+	if err := EncodeGIF(context.Background(), frames, &buf, GIFOptions{FrameRate: 20}); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Image) != 3 {
+		t.Fatalf("decoded %d frames, want 3", len(g.Image))
+	}
+	for i, d := range g.Delay {
+		// 20 fps is 5 hundredths of a second per frame
+		if d != 5 {
+			t.Errorf("frame %d delay = %d, want 5", i, d)
+		}
+	}
+}
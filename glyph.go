@@ -0,0 +1,86 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"unicode"
+)
+
+// glyphHeight is the number of pixel rows in a glyph; glyphs are 3 wide
+const glyphHeight = 5
+
+// glyphs are 3x5 bitmaps, one byte per row whose three low bits are the
+// pixels, left to right. Letters are uppercase only; characters missing
+// here are drawn as a space.
+var glyphs = map[rune][glyphHeight]byte{
+	'+': {0b000, 0b010, 0b111, 0b010, 0b000},
+	'-': {0b000, 0b000, 0b111, 0b000, 0b000},
+	'_': {0b000, 0b000, 0b000, 0b000, 0b111},
+	'.': {0b000, 0b000, 0b000, 0b000, 0b010},
+	'0': {0b111, 0b101, 0b101, 0b101, 0b111},
+	'1': {0b010, 0b110, 0b010, 0b010, 0b111},
+	'2': {0b111, 0b001, 0b111, 0b100, 0b111},
+	'3': {0b111, 0b001, 0b111, 0b001, 0b111},
+	'4': {0b101, 0b101, 0b111, 0b001, 0b001},
+	'5': {0b111, 0b100, 0b111, 0b001, 0b111},
+	'6': {0b111, 0b100, 0b111, 0b101, 0b111},
+	'7': {0b111, 0b001, 0b010, 0b010, 0b010},
+	'8': {0b111, 0b101, 0b111, 0b101, 0b111},
+	'9': {0b111, 0b101, 0b111, 0b001, 0b111},
+	'A': {0b010, 0b101, 0b111, 0b101, 0b101},
+	'B': {0b110, 0b101, 0b110, 0b101, 0b110},
+	'C': {0b011, 0b100, 0b100, 0b100, 0b011},
+	'D': {0b110, 0b101, 0b101, 0b101, 0b110},
+	'E': {0b111, 0b100, 0b110, 0b100, 0b111},
+	'F': {0b111, 0b100, 0b110, 0b100, 0b100},
+	'G': {0b011, 0b100, 0b101, 0b101, 0b011},
+	'H': {0b101, 0b101, 0b111, 0b101, 0b101},
+	'I': {0b111, 0b010, 0b010, 0b010, 0b111},
+	'J': {0b001, 0b001, 0b001, 0b101, 0b010},
+	'K': {0b101, 0b101, 0b110, 0b101, 0b101},
+	'L': {0b100, 0b100, 0b100, 0b100, 0b111},
+	'M': {0b101, 0b111, 0b111, 0b101, 0b101},
+	'N': {0b110, 0b101, 0b101, 0b101, 0b101},
+	'O': {0b010, 0b101, 0b101, 0b101, 0b010},
+	'P': {0b110, 0b101, 0b110, 0b100, 0b100},
+	'Q': {0b010, 0b101, 0b101, 0b110, 0b011},
+	'R': {0b110, 0b101, 0b110, 0b101, 0b101},
+	'S': {0b011, 0b100, 0b010, 0b001, 0b110},
+	'T': {0b111, 0b010, 0b010, 0b010, 0b010},
+	'U': {0b101, 0b101, 0b101, 0b101, 0b111},
+	'V': {0b101, 0b101, 0b101, 0b101, 0b010},
+	'W': {0b101, 0b101, 0b111, 0b111, 0b101},
+	'X': {0b101, 0b101, 0b010, 0b101, 0b101},
+	'Y': {0b101, 0b101, 0b010, 0b010, 0b010},
+	'Z': {0b111, 0b001, 0b010, 0b100, 0b111},
+}
+
+// textWidth is the width in frame pixels of text drawn by textRects
+func textWidth(text string, pixel int) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return (4*n - 1) * pixel // Three pixels per glyph, one between glyphs
+}
+
+// textRects returns the lit pixels of text drawn from the top-left corner
+// x, y, each glyph pixel pixel frame pixels square. Lowercase letters are
+// drawn as uppercase.
+func textRects(text string, x, y, pixel int) []image.Rectangle {
+	var rects []image.Rectangle
+	for _, ch := range text {
+		glyph := glyphs[unicode.ToUpper(ch)]
+		for row, bits := range glyph {
+			for col := 0; col < 3; col++ {
+				if bits&(0b100>>col) == 0 {
+					continue
+				}
+				px := x + col*pixel
+				py := y + row*pixel
+				rects = append(rects, image.Rect(px, py, px+pixel, py+pixel))
+			}
+		}
+		x += 4 * pixel
+	}
+	return rects
+}
@@ -0,0 +1,47 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"image/color"
+)
+
+var (
+	gridColor       = color.RGBA{255, 255, 255, 48}
+	gridOriginColor = color.RGBA{255, 60, 60, 255}
+)
+
+const (
+	// gridMinSpacing is the closest the debug grid's lines get, in pixels.
+	// Zoomed out further, only the origin marker is drawn.
+	gridMinSpacing = 4
+	gridOriginArm  = 6 // Pixels each arm of the origin crosshair reaches
+)
+
+// SetDebugGrid draws thin lines along the grid's rows and columns, and a
+// red crosshair at grid position (0, 0), to help line up lands and
+// processes. The grid follows SetScale and sits above the lands, below
+// edges' flow dots and sprites.
+func (r *Renderer) SetDebugGrid(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.debugGrid = enabled
+}
+
+// gridRects returns the 1-pixel-wide lines of the debug grid for a frame,
+// and the two bars of its origin crosshair
+func gridRects(tileSize, width, height int) (lines, origin []image.Rectangle) {
+	const originX, originY = 100, 100
+	if tileSize >= gridMinSpacing {
+		for x := originX % tileSize; x < width; x += tileSize {
+			lines = append(lines, image.Rect(x, 0, x+1, height))
+		}
+		for y := originY % tileSize; y < height; y += tileSize {
+			lines = append(lines, image.Rect(0, y, width, y+1))
+		}
+	}
+	origin = []image.Rectangle{
+		image.Rect(originX-gridOriginArm, originY, originX+gridOriginArm+1, originY+1),
+		image.Rect(originX, originY-gridOriginArm, originX+1, originY+gridOriginArm+1),
+	}
+	return lines, origin
+}
@@ -0,0 +1,32 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"testing"
+)
+
+func TestDebugGridMarksOrigin(t *testing.T) {
+	state := &staticState{}
+
+	// The origin is the top-left corner of the tile at grid (0, 0), at
+	// (100, 100) whatever the scale
+	for _, scale := range []float64{1, 0.05} {
+		r := newTestRenderer(t, Options{Width: 320, Height: 240, Scale: scale})
+		if got := r.RenderAt(state, 0).(*image.RGBA).RGBAAt(100, 100); got == gridOriginColor {
+			t.Fatalf("scale %v: origin marked without the debug grid", scale)
+		}
+		r.SetDebugGrid(true)
+		if got := r.RenderAt(state, 0).(*image.RGBA).RGBAAt(100, 100); got != gridOriginColor {
+			t.Errorf("scale %v: pixel at the origin is %v, want %v", scale, got, gridOriginColor)
+		}
+	}
+}
+
+func TestDebugGridSkipsDenseLines(t *testing.T) {
+	if lines, _ := gridRects(64, 320, 240); len(lines) != 5+4 {
+		t.Errorf("got %d lines at 64 pixels a tile, want 9", len(lines))
+	}
+	if lines, origin := gridRects(3, 320, 240); len(lines) != 0 || len(origin) != 2 {
+		t.Errorf("got %d lines and %d origin bars at 3 pixels a tile, want only the origin", len(lines), len(origin))
+	}
+}
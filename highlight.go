@@ -0,0 +1,52 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"image/color"
+	"math"
+)
+
+// highlightColor is the glow drawn around highlighted lands and processes
+var highlightColor = color.RGBA{255, 240, 160, 255}
+
+// highlightWidth is the thickness of the glow around a highlighted land
+const highlightWidth = 3
+
+// Highlight draws a pulsing glow around the lands and processes with the
+// given IDs, replacing any previous highlights. IDs that match nothing
+// are ignored.
+func (r *Renderer) Highlight(ids ...string) {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Replaced rather than modified, so frames already drawing keep a
+	// consistent set
+	r.highlights = set
+}
+
+// ClearHighlights removes all highlights
+func (r *Renderer) ClearHighlights() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.highlights = nil
+}
+
+// highlightAlpha is the glow's opacity at the given animation phase
+func highlightAlpha(phase float64) float64 {
+	return 0.35 + 0.4*math.Abs(math.Sin(phase/12))
+}
+
+// highlightFrame returns the four strips framing a square tile at x, y
+func highlightFrame(x, y, size int) [4]image.Rectangle {
+	w := highlightWidth
+	return [4]image.Rectangle{
+		image.Rect(x-w, y-w, x+size+w, y),           // Top
+		image.Rect(x-w, y+size, x+size+w, y+size+w), // Bottom
+		image.Rect(x-w, y, x, y+size),               // Left
+		image.Rect(x+size, y, x+size+w, y+size),     // Right
+	}
+}
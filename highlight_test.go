@@ -0,0 +1,35 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestHighlightGlowsAroundLand(t *testing.T) {
+	r := newTestRenderer(t, Options{})
+	state := &staticState{lands: []Land{{ID: "a", Type: "forest"}}}
+	plain := r.RenderAt(state, 0).(*image.RGBA)
+
+	// An unknown ID matches nothing, so the frame is unchanged
+	r.Highlight("missing")
+	if img := r.RenderAt(state, 0).(*image.RGBA); !bytes.Equal(img.Pix, plain.Pix) {
+		t.Error("highlighting an unknown ID changed the frame")
+	}
+
+	// The land's tile covers 62 pixels from (100, 100); the glow frames it
+	r.Highlight("a")
+	img := r.RenderAt(state, 0).(*image.RGBA)
+	bg := DefaultPalette().Background
+	for _, rect := range highlightFrame(100, 100, 62) {
+		c := img.RGBAAt(rect.Min.X+1, rect.Min.Y+1)
+		if c.R <= bg.R || c.G <= bg.G || c.B <= bg.B {
+			t.Errorf("glow pixel at %v is %v, want brighter than the background", rect.Min, c)
+		}
+	}
+
+	r.ClearHighlights()
+	if img := r.RenderAt(state, 0).(*image.RGBA); !bytes.Equal(img.Pix, plain.Pix) {
+		t.Error("ClearHighlights left a glow")
+	}
+}
@@ -0,0 +1,135 @@
+package nimsforestsprites
+
+import "time"
+
+// interpolator blends process positions between the two most recent state
+// updates so motion stays smooth when updates arrive slower than frames.
+// Processes are matched by ID; new ones fade in and removed ones fade out.
+type interpolator struct {
+	prev      []Process
+	curr      []Process
+	lands     []Land
+	edges     []Edge
+	updatedAt time.Time
+	interval  time.Duration // Expected time until the next update
+}
+
+func newInterpolator(frameDuration time.Duration) *interpolator {
+	return &interpolator{interval: frameDuration}
+}
+
+// update snapshots state as the new interpolation target. The previous
+// target becomes the starting point, and the interval is estimated from the
+// time since the last update.
+func (ip *interpolator) update(state State, now time.Time) {
+	if state == nil {
+		ip.prev, ip.curr, ip.lands, ip.edges = nil, nil, nil, nil
+		ip.updatedAt = time.Time{}
+		return
+	}
+
+	if !ip.updatedAt.IsZero() {
+		if d := now.Sub(ip.updatedAt); d > 0 {
+			ip.interval = d
+		}
+		ip.prev = ip.curr
+	}
+	ip.curr = state.Processes()
+	ip.lands = state.Lands()
+	ip.edges = nil
+	if es, ok := state.(EdgeState); ok {
+		ip.edges = es.Edges()
+	}
+	ip.updatedAt = now
+}
+
+// at returns the interpolated state for the given time
+func (ip *interpolator) at(now time.Time) *interpolatedState {
+	t := 1.0
+	if ip.interval > 0 {
+		t = float64(now.Sub(ip.updatedAt)) / float64(ip.interval)
+		t = max(0, min(1, t))
+	}
+
+	prevByID := make(map[string]Process, len(ip.prev))
+	for _, p := range ip.prev {
+		prevByID[p.ID] = p
+	}
+
+	s := &interpolatedState{
+		lands:     ip.lands,
+		edges:     ip.edges,
+		processes: make([]Process, 0, len(ip.curr)+len(ip.prev)),
+		alpha:     make(map[string]float64),
+	}
+
+	seen := make(map[string]bool, len(ip.curr))
+	for _, p := range ip.curr {
+		seen[p.ID] = true
+		from, ok := prevByID[p.ID]
+		if !ok {
+			// New process: fade in at its target position
+			if ip.prev != nil {
+				s.alpha[p.ID] = t
+			}
+			s.processes = append(s.processes, p)
+			continue
+		}
+		p.X = lerp(from.X, p.X, t)
+		p.Y = lerp(from.Y, p.Y, t)
+		if p.Progress >= from.Progress {
+			// Progress wraps from 1 back to 0; don't animate it backwards
+			p.Progress = lerp(from.Progress, p.Progress, t)
+		}
+		s.processes = append(s.processes, p)
+	}
+
+	// Removed processes fade out where they were
+	for _, p := range ip.prev {
+		if !seen[p.ID] && t < 1 {
+			s.alpha[p.ID] = 1 - t
+			s.processes = append(s.processes, p)
+		}
+	}
+
+	return s
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// interpolatedState is the State handed to the render paths while
+// interpolating. It carries a per-process alpha for fading.
+type interpolatedState struct {
+	lands     []Land
+	processes []Process
+	edges     []Edge
+	alpha     map[string]float64
+}
+
+func (s *interpolatedState) Lands() []Land        { return s.lands }
+func (s *interpolatedState) Processes() []Process { return s.processes }
+func (s *interpolatedState) Edges() []Edge        { return s.edges }
+
+// processAlpha returns the fade applied to a process, 1 when it is fully
+// visible or the state carries no fading information. Spawn animation
+// fades combine with interpolation fades.
+func processAlpha(state State, id string) float64 {
+	alpha := 1.0
+	if s, ok := state.(*trailState); ok {
+		state = s.State
+	}
+	if s, ok := state.(*spawnState); ok {
+		if a, ok := s.alpha[id]; ok {
+			alpha = a
+		}
+		state = s.State
+	}
+	if s, ok := state.(*interpolatedState); ok {
+		if a, ok := s.alpha[id]; ok {
+			alpha *= a
+		}
+	}
+	return alpha
+}
@@ -0,0 +1,56 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"testing"
+)
+
+// spriteCenterX returns the mean x of the pixels drawn exactly in c, or -1
+// if there are none
+func spriteCenterX(img *image.RGBA, c [4]uint8) float64 {
+	sum, n := 0, 0
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			i := img.PixOffset(x, y)
+			if [4]uint8(img.Pix[i:i+4]) == c {
+				sum += x
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		return -1
+	}
+	return float64(sum) / float64(n)
+}
+
+func TestInterpolatedSpriteSitsBetweenCells(t *testing.T) {
+	r := newTestRenderer(t, Options{Width: 320, Height: 240, FrameRate: 10, Interpolate: true, Manual: true})
+	tree := DefaultPalette().ProcessColor("tree")
+	treeRGBA := [4]uint8{tree.R, tree.G, tree.B, tree.A}
+	at := func(x float64) State {
+		return &staticState{processes: []Process{{ID: "p", Type: "tree", X: x}}}
+	}
+
+	// Cell centers are 64 pixels apart, starting at x = 132. The second
+	// update comes four frames after the first, so two frames after it the
+	// sprite is halfway there.
+	r.Update(at(0))
+	from := spriteCenterX(r.Step().(*image.RGBA), treeRGBA)
+	for i := 0; i < 3; i++ {
+		r.Step()
+	}
+	r.Update(at(1))
+	r.Step()
+	mid := spriteCenterX(r.Step().(*image.RGBA), treeRGBA)
+	r.Step()
+	to := spriteCenterX(r.Step().(*image.RGBA), treeRGBA)
+
+	if from != 132 || to != 196 {
+		t.Fatalf("sprite at x = %v before and %v after interpolating, want 132 and 196", from, to)
+	}
+	if mid != 164 {
+		t.Errorf("sprite halfway between updates at x = %v, want 164", mid)
+	}
+}
@@ -0,0 +1,51 @@
+package nimsforestsprites
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONState is a State decoded from a JSON document of the form
+// {"lands": [...], "processes": [...]}, letting non-Go tools feed the
+// renderer. Field names match Land and Process and are case-insensitive.
+type JSONState struct {
+	staticState
+}
+
+// UnmarshalJSON decodes and validates the document. Processes must refer
+// to a land in the same document; Progress is clamped to [0, 1].
+func (s *JSONState) UnmarshalJSON(data []byte) error {
+	var doc struct {
+		Lands     []Land    `json:"lands"`
+		Processes []Process `json:"processes"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	landIDs := make(map[string]bool, len(doc.Lands))
+	for _, land := range doc.Lands {
+		landIDs[land.ID] = true
+	}
+	for i := range doc.Processes {
+		proc := &doc.Processes[i]
+		if !landIDs[proc.LandID] {
+			return fmt.Errorf("process %q references unknown land %q", proc.ID, proc.LandID)
+		}
+		proc.Progress = max(0, min(1, proc.Progress))
+	}
+
+	s.lands = doc.Lands
+	s.processes = doc.Processes
+	return nil
+}
+
+// LoadStateJSON reads a JSONState from r
+func LoadStateJSON(r io.Reader) (*JSONState, error) {
+	s := &JSONState{}
+	if err := json.NewDecoder(r).Decode(s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
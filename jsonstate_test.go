@@ -0,0 +1,32 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestLoadStateJSONRenders(t *testing.T) {
+	doc := `{
+		"lands": [{"id": "a", "type": "forest"}, {"id": "b", "type": "water", "x": 1}],
+		"processes": [{"id": "p", "landID": "b", "type": "tree", "progress": 1.5, "x": 1}]
+	}`
+	s, err := LoadStateJSON(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if procs := s.Processes(); len(s.Lands()) != 2 || len(procs) != 1 || procs[0].Progress != 1 {
+		t.Fatalf("decoded lands %+v and processes %+v, want 2 lands and one process at Progress 1", s.Lands(), procs)
+	}
+
+	r := newTestRenderer(t, Options{Width: 320, Height: 240})
+	img := r.RenderAt(s, 0).(*image.RGBA)
+	if got, want := img.RGBAAt(196, 132), DefaultPalette().ProcessColor("tree"); got != want {
+		t.Errorf("pixel at the process is %v, want %v", got, want)
+	}
+
+	bad := `{"lands": [], "processes": [{"id": "p", "landID": "gone"}]}`
+	if _, err := LoadStateJSON(strings.NewReader(bad)); err == nil {
+		t.Error("a process on an unknown land decoded without error")
+	}
+}
@@ -0,0 +1,120 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"image/color"
+	"slices"
+)
+
+var (
+	legendBoxColor  = color.RGBA{0, 0, 0, 170} // Translucent panel behind the legend
+	legendTextColor = color.RGBA{220, 220, 220, 255}
+)
+
+const (
+	legendPixel   = 2  // Size of one glyph pixel
+	legendSwatch  = 10 // Side of a land swatch
+	legendPadding = 6  // Space inside the panel's edge
+	legendGap     = 5  // Space between a swatch and its label
+	legendRow     = glyphHeight*legendPixel + 4
+	legendColumn  = 12 // Space between wrapped columns
+)
+
+// SetLegendEnabled turns on a key in the top-right corner of the frame
+// listing each land and process type in the current state next to a swatch
+// of its color: land types first, with square swatches, then process types
+// with smaller ones, each group sorted by name. A list too long for the
+// top half of the frame wraps into further columns to the left.
+func (r *Renderer) SetLegendEnabled(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.legend = enabled
+}
+
+// legendRect is one filled rectangle of the legend
+type legendRect struct {
+	rect image.Rectangle
+	c    color.RGBA
+}
+
+// legendLayout returns the rectangles that draw the legend for state in a
+// frame of the given size, starting with the panel behind it, or nil when
+// the state has no types to list
+func legendLayout(state State, palette *Palette, width, height int) []legendRect {
+	type entry struct {
+		label   string
+		c       color.RGBA
+		process bool
+	}
+	var entries []entry
+	for _, t := range legendTypes(state.Lands(), func(l Land) string { return l.Type }) {
+		entries = append(entries, entry{legendLabel(t), palette.LandColor(t), false})
+	}
+	for _, t := range legendTypes(state.Processes(), func(p Process) string { return p.Type }) {
+		entries = append(entries, entry{legendLabel(t), palette.ProcessColor(t), true})
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	labelWidth := 0
+	for _, e := range entries {
+		labelWidth = max(labelWidth, textWidth(e.label, legendPixel))
+	}
+	// As many rows as fit between the top margin and the middle of the frame
+	room := height/2 - 10 - 2*legendPadding + legendRow - legendSwatch
+	rows := min(len(entries), max(1, room/legendRow))
+	cols := (len(entries) + rows - 1) / rows
+	colWidth := legendSwatch + legendGap + labelWidth
+
+	boxW := 2*legendPadding + cols*colWidth + (cols-1)*legendColumn
+	boxH := 2*legendPadding + rows*legendRow - (legendRow - legendSwatch)
+	x0, y0 := width-10-boxW, 10
+	rects := []legendRect{{image.Rect(x0, y0, x0+boxW, y0+boxH), legendBoxColor}}
+
+	for i, e := range entries {
+		x := x0 + legendPadding + i/rows*(colWidth+legendColumn)
+		y := y0 + legendPadding + i%rows*legendRow
+		swatch := image.Rect(x, y, x+legendSwatch, y+legendSwatch)
+		if e.process {
+			swatch = swatch.Inset(2)
+		}
+		rects = append(rects, legendRect{swatch, e.c})
+		for _, px := range textRects(e.label, x+legendSwatch+legendGap, y, legendPixel) {
+			rects = append(rects, legendRect{px, legendTextColor})
+		}
+	}
+	return rects
+}
+
+// legendBox returns the panel the legend for state covers, or an empty
+// rectangle when there is no legend
+func legendBox(state State, width, height int) image.Rectangle {
+	if state == nil {
+		return image.Rectangle{}
+	}
+	// Colors don't affect the layout
+	if rects := legendLayout(state, &Palette{}, width, height); len(rects) > 0 {
+		return rects[0].rect
+	}
+	return image.Rectangle{}
+}
+
+// legendTypes returns the distinct types of items, sorted
+func legendTypes[T any](items []T, typeOf func(T) string) []string {
+	var types []string
+	for _, item := range items {
+		types = append(types, typeOf(item))
+	}
+	slices.Sort(types)
+	return slices.Compact(types)
+}
+
+// legendLabel is the text shown for a type; the empty type uses the
+// palette's default color
+func legendLabel(t string) string {
+	if t == "" {
+		return "default"
+	}
+	return t
+}
@@ -0,0 +1,61 @@
+package nimsforestsprites
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// countColor returns how many pixels of img inside rect are exactly c
+func countColor(img *image.RGBA, rect image.Rectangle, c color.RGBA) int {
+	n := 0
+	rect = rect.Intersect(img.Bounds())
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			if img.RGBAAt(x, y) == c {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestLegendDrawsProcessSwatches(t *testing.T) {
+	r := newTestRenderer(t, Options{Width: 400, Height: 300})
+	state := &staticState{
+		lands: []Land{{ID: "a", Type: "forest"}, {ID: "b", X: 1, Type: "forest"}},
+		processes: []Process{
+			{ID: "p", LandID: "a", Type: "tree"},
+			{ID: "q", LandID: "b", Type: "mana", X: 1},
+		},
+	}
+	palette := DefaultPalette()
+
+	// The lands and sprites stay left of x = 250, under the legend's corner
+	corner := image.Rect(250, 0, 400, 100)
+	plain := r.RenderAt(state, 0).(*image.RGBA)
+	r.SetLegendEnabled(true)
+	keyed := r.RenderAt(state, 0).(*image.RGBA)
+
+	for _, typ := range []string{"tree", "mana"} {
+		c := palette.ProcessColor(typ)
+		if n := countColor(plain, corner, c); n != 0 {
+			t.Fatalf("%s color drawn in the corner without a legend", typ)
+		}
+		if n := countColor(keyed, corner, c); n < 36 {
+			t.Errorf("legend has %d pixels of the %s swatch, want a 6x6 square", n, typ)
+		}
+	}
+}
+
+func TestLegendWrapsWithinHalfTheFrame(t *testing.T) {
+	var processes []Process
+	for i := 0; i < 40; i++ {
+		processes = append(processes, Process{ID: fmt.Sprint(i), Type: fmt.Sprintf("type%02d", i)})
+	}
+	box := legendBox(&staticState{processes: processes}, 800, 200)
+	if box.Empty() || box.Max.Y > 100 || box.Max.X > 800 {
+		t.Fatalf("legend panel %v does not fit the top half of an 800x200 frame", box)
+	}
+}
@@ -0,0 +1,32 @@
+package nimsforestsprites
+
+import "image"
+
+// Step renders the next frame of the current state for Options.Manual,
+// e.g. from a requestAnimationFrame callback. Each call advances the tick
+// and the animation by exactly one frame at the configured FrameRate, and
+// interpolation is timed by the same step count rather than Options.Clock,
+// so a sequence of calls is reproducible. It returns nil once the renderer
+// is closed.
+func (r *Renderer) Step() image.Image {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.tick++
+	r.advanceLocked(r.frameDuration())
+	tick := r.tick
+	phase := animationPhase(r.elapsed)
+	state := r.frameStateLocked()
+	r.mu.Unlock()
+
+	start := r.opts.Clock.Now()
+	frame := r.renderFrameSoftwareAt(state, phase)
+	r.emitFrameStats(state, tick, r.opts.Clock.Now().Sub(start), false)
+
+	r.mu.Lock()
+	r.lastFrame = frame
+	r.mu.Unlock()
+	return frame
+}
@@ -0,0 +1,47 @@
+package nimsforestsprites
+
+import (
+	"context"
+	"image"
+	"runtime"
+	"testing"
+)
+
+func TestManualStepAdvancesWithoutGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	r := newTestRenderer(t, Options{FrameRate: 30, Manual: true})
+	r.Update(NewMockStateSeed(1))
+
+	// The frame indicator moves 2 pixels right per animation step along
+	// y = 10, starting at x = 10
+	indicatorX := func(img *image.RGBA) int {
+		for x := 10; x < 140; x++ {
+			if c := img.RGBAAt(x, 11); c.G > 150 {
+				return x
+			}
+		}
+		return -1
+	}
+	last := -1
+	for i := 1; i <= 5; i++ {
+		img := r.Step().(*image.RGBA)
+		r.mu.RLock()
+		tick := r.tick
+		r.mu.RUnlock()
+		if tick != i {
+			t.Fatalf("tick is %d after %d steps", tick, i)
+		}
+		if x := indicatorX(img); x <= last {
+			t.Fatalf("step %d: frame indicator at x = %d, not past %d", i, x, last)
+		} else {
+			last = x
+		}
+	}
+
+	if _, ok := <-r.Frames(context.Background()); ok {
+		t.Error("Frames produced a frame in Manual mode")
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines after stepping, %d before", after, before)
+	}
+}
@@ -0,0 +1,161 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"net/http"
+	"sync"
+)
+
+const mjpegBoundary = "frame"
+
+// ServeMJPEG serves frames as a multipart/x-mixed-replace JPEG stream at
+// /stream on addr, so the visualization can be watched in a browser. Each
+// frame is encoded once and fanned out to every viewer; viewers that fall
+// behind skip to the latest frame instead of slowing the others down. It
+// blocks until ctx is cancelled or the server fails.
+func ServeMJPEG(ctx context.Context, addr string, frames <-chan image.Image) error {
+	hub := newMJPEGHub()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stream", hub.serveHTTP)
+	srv := &http.Server{Addr: addr, Handler: mux}
+
+	// The encoder stops with the server, however ServeMJPEG returns
+	ctx, cancel := context.WithCancel(ctx)
+	var encoder sync.WaitGroup
+	defer encoder.Wait()
+	defer cancel()
+
+	encoder.Add(1)
+	go func() {
+		defer encoder.Done()
+		defer hub.close()
+		var buf bytes.Buffer
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case frame, ok := <-frames:
+				if !ok {
+					return
+				}
+				buf.Reset()
+				if err := jpeg.Encode(&buf, frame, &jpeg.Options{Quality: 80}); err != nil {
+					continue
+				}
+				hub.broadcast(bytes.Clone(buf.Bytes()))
+			}
+		}
+	}()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case <-ctx.Done():
+		// Close rather than Shutdown: streams never go idle
+		srv.Close()
+		<-errCh
+		return nil
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// mjpegHub fans encoded frames out to connected viewers. Each viewer has a
+// one-slot channel holding the newest frame it has not sent yet.
+type mjpegHub struct {
+	mu      sync.Mutex
+	clients map[chan []byte]struct{}
+	closed  bool
+}
+
+func newMJPEGHub() *mjpegHub {
+	return &mjpegHub{clients: make(map[chan []byte]struct{})}
+}
+
+func (h *mjpegHub) subscribe() (chan []byte, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, false
+	}
+	ch := make(chan []byte, 1)
+	h.clients[ch] = struct{}{}
+	return ch, true
+}
+
+func (h *mjpegHub) unsubscribe(ch chan []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[ch]; ok {
+		delete(h.clients, ch)
+		close(ch)
+	}
+}
+
+func (h *mjpegHub) broadcast(frame []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.clients {
+		// Replace a frame the viewer hasn't picked up yet
+		sendFrame(ch, frame, DropOldest, nil, nil)
+	}
+}
+
+func (h *mjpegHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.clients {
+		delete(h.clients, ch)
+		close(ch)
+	}
+}
+
+func (h *mjpegHub) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	ch, ok := h.subscribe()
+	if !ok {
+		http.Error(w, "stream ended", http.StatusServiceUnavailable)
+		return
+	}
+	defer h.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary="+mjpegBoundary)
+	w.Header().Set("Cache-Control", "no-cache")
+	flusher, _ := w.(http.Flusher)
+
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case frame, ok := <-ch:
+			if !ok {
+				return
+			}
+			_, err := fmt.Fprintf(w, "--%s\r\nContent-Type: image/jpeg\r\nContent-Length: %d\r\n\r\n", mjpegBoundary, len(frame))
+			if err == nil {
+				_, err = w.Write(frame)
+			}
+			if err == nil {
+				_, err = w.Write([]byte("\r\n"))
+			}
+			if err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
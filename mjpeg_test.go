@@ -0,0 +1,131 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/jpeg"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// readMJPEGFrame decodes the first JPEG of a multipart stream
+func readMJPEGFrame(t *testing.T, contentType string, body io.Reader) image.Image {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != "multipart/x-mixed-replace" {
+		t.Fatalf("Content-Type %q is not multipart/x-mixed-replace", contentType)
+	}
+	part, err := multipart.NewReader(body, params["boundary"]).NextPart()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := jpeg.Decode(part)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestMJPEGHubServesFramesAndReleasesViewers(t *testing.T) {
+	hub := newMJPEGHub()
+	viewers := func() int {
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+		return len(hub.clients)
+	}
+	serve := func(ctx context.Context) (*httptest.ResponseRecorder, chan struct{}) {
+		rec := httptest.NewRecorder()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			hub.serveHTTP(rec, httptest.NewRequest("GET", "/stream", nil).WithContext(ctx))
+		}()
+		for deadline := time.Now().Add(time.Second); viewers() == 0; {
+			if time.Now().After(deadline) {
+				t.Fatal("viewer never subscribed")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return rec, done
+	}
+
+	// A viewer that disconnects is unsubscribed and its handler returns
+	ctx, cancel := context.WithCancel(context.Background())
+	_, done := serve(ctx)
+	cancel()
+	<-done
+	if n := viewers(); n != 0 {
+		t.Fatalf("%d viewers left after disconnecting", n)
+	}
+
+	// The queued frame goes out before the stream ends
+	var frame bytes.Buffer
+	if err := jpeg.Encode(&frame, image.NewRGBA(image.Rect(0, 0, 32, 24)), nil); err != nil {
+		t.Fatal(err)
+	}
+	rec, done := serve(context.Background())
+	hub.broadcast(frame.Bytes())
+	hub.close()
+	<-done
+	img := readMJPEGFrame(t, rec.Header().Get("Content-Type"), rec.Body)
+	if img.Bounds() != image.Rect(0, 0, 32, 24) {
+		t.Errorf("streamed frame is %v, want 32x24", img.Bounds())
+	}
+}
+
+func TestServeMJPEGStreamsToHTTPClient(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	frames := make(chan image.Image)
+	served := make(chan error, 1)
+	go func() { served <- ServeMJPEG(ctx, addr, frames) }()
+	defer func() {
+		cancel()
+		if err := <-served; err != nil {
+			t.Errorf("ServeMJPEG: %v", err)
+		}
+	}()
+
+	// Headers go out with the first frame, so keep frames coming
+	frame := newTestRenderer(t, Options{}).Render(NewMockStateSeed(1))
+	go func() {
+		for {
+			select {
+			case frames <- frame:
+			case <-ctx.Done():
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	var resp *http.Response
+	for i := 0; i < 50; i++ {
+		if resp, err = http.Get("http://" + addr + "/stream"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	img := readMJPEGFrame(t, resp.Header.Get("Content-Type"), resp.Body)
+	if img.Bounds() != image.Rect(0, 0, 320, 240) {
+		t.Errorf("streamed frame is %v, want 320x240", img.Bounds())
+	}
+}
@@ -0,0 +1,108 @@
+package nimsforestsprites
+
+import (
+	"image/color"
+	"math"
+)
+
+// Palette maps land and process types to the colors they are drawn with
+type Palette struct {
+	Background color.RGBA
+	Lands      map[string]color.RGBA // Keyed by Land.Type
+	Processes  map[string]color.RGBA // Keyed by Process.Type
+
+	DefaultLand    color.RGBA // Used for land types missing from Lands
+	DefaultProcess color.RGBA // Used for process types missing from Processes
+
+	// Statuses outlines processes by Process.Status. Processes whose status
+	// is empty or missing here are drawn in their type color only.
+	Statuses map[string]StatusStyle
+}
+
+// StatusStyle is the outline drawn around a process with a given status
+type StatusStyle struct {
+	Color color.RGBA
+	Pulse bool // Animate the outline's opacity
+}
+
+// DefaultPalette returns the built-in dark palette
+func DefaultPalette() *Palette {
+	return &Palette{
+		Background: color.RGBA{20, 25, 30, 255},
+		Lands: map[string]color.RGBA{
+			"mana":   {80, 60, 120, 255},
+			"forest": {40, 80, 50, 255},
+			"water":  {40, 60, 100, 255},
+		},
+		Processes: map[string]color.RGBA{
+			"tree": {60, 150, 60, 255},
+			"nim":  {200, 180, 100, 255},
+			"mana": {150, 100, 200, 255},
+		},
+		DefaultLand:    color.RGBA{60, 70, 60, 255},
+		DefaultProcess: color.RGBA{150, 150, 150, 255},
+		Statuses: map[string]StatusStyle{
+			"error":   {Color: color.RGBA{220, 50, 50, 255}},
+			"stalled": {Color: color.RGBA{230, 170, 40, 255}, Pulse: true},
+		},
+	}
+}
+
+// ColorBlindSafePalette returns a dark palette built from the Okabe–Ito
+// colors, which stay distinguishable under the common color vision
+// deficiencies. It passes Validate.
+func ColorBlindSafePalette() *Palette {
+	return &Palette{
+		Background: color.RGBA{20, 25, 30, 255},
+		Lands: map[string]color.RGBA{
+			"mana":   {0, 114, 178, 255},  // Blue
+			"forest": {0, 158, 115, 255},  // Bluish green
+			"water":  {86, 180, 233, 255}, // Sky blue
+		},
+		Processes: map[string]color.RGBA{
+			"tree": {240, 228, 66, 255},  // Yellow
+			"nim":  {230, 159, 0, 255},   // Orange
+			"mana": {204, 121, 167, 255}, // Reddish purple
+		},
+		DefaultLand:    color.RGBA{90, 90, 90, 255},
+		DefaultProcess: color.RGBA{255, 255, 255, 255},
+		Statuses: map[string]StatusStyle{
+			"error":   {Color: color.RGBA{213, 94, 0, 255}},                // Vermillion
+			"stalled": {Color: color.RGBA{86, 180, 233, 255}, Pulse: true}, // Sky blue
+		},
+	}
+}
+
+// LandColor returns the color for a land type
+func (p *Palette) LandColor(landType string) color.RGBA {
+	if c, ok := p.Lands[landType]; ok {
+		return c
+	}
+	return p.DefaultLand
+}
+
+// ProcessColor returns the color for a process type
+func (p *Palette) ProcessColor(procType string) color.RGBA {
+	if c, ok := p.Processes[procType]; ok {
+		return c
+	}
+	return p.DefaultProcess
+}
+
+// statusOutline returns the outline color for a status and its opacity at
+// the given animation phase. ok is false when the status has no outline.
+func (p *Palette) statusOutline(status string, phase float64) (c color.RGBA, alpha float64, ok bool) {
+	if status == "" {
+		return color.RGBA{}, 0, false
+	}
+	style, ok := p.Statuses[status]
+	if !ok {
+		return color.RGBA{}, 0, false
+	}
+
+	alpha = 1
+	if style.Pulse {
+		alpha = 0.4 + 0.6*math.Abs(math.Sin(phase/8.0))
+	}
+	return style.Color, alpha, true
+}
@@ -0,0 +1,50 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestCustomLandColorUsedForTile(t *testing.T) {
+	p := DefaultPalette()
+	p.Lands["forest"] = color.RGBA{230, 0, 0, 255}
+	r := newTestRenderer(t, Options{Palette: p})
+
+	state := &staticState{lands: []Land{{ID: "a", Type: "forest"}}}
+	got := r.RenderAt(state, 0).(*image.RGBA).RGBAAt(131, 131)
+
+	// Tiles pulse slightly translucent, so the dark background shows
+	// through a little
+	if got.R < 180 || got.G > 40 || got.B > 40 {
+		t.Fatalf("tile center is %v, want the custom red", got)
+	}
+}
+
+func TestErrorStatusOutlinesSprite(t *testing.T) {
+	r := newTestRenderer(t, Options{})
+	at := func(status string) *image.RGBA {
+		state := &staticState{processes: []Process{{ID: "p", Type: "tree", Status: status}}}
+		return r.RenderAt(state, 0).(*image.RGBA)
+	}
+	p := DefaultPalette()
+	red := p.Statuses["error"].Color
+
+	// The sprite has a radius of 8 around (132, 132), the outline 10
+	img := at("error")
+	for _, pt := range []image.Point{{141, 132}, {123, 132}, {132, 141}, {132, 123}} {
+		if got := img.RGBAAt(pt.X, pt.Y); got != red {
+			t.Errorf("outline pixel at %v is %v, want %v", pt, got, red)
+		}
+	}
+	if got := img.RGBAAt(132, 132); got != p.ProcessColor("tree") {
+		t.Errorf("sprite center is %v, want the type color", got)
+	}
+
+	// No status and unknown statuses draw no outline
+	for _, status := range []string{"", "unknown"} {
+		if got := at(status).RGBAAt(141, 132); got != p.Background {
+			t.Errorf("status %q: pixel outside the sprite is %v, want the background", status, got)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package nimsforestsprites
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"time"
+)
+
+// parityStartTimeout bounds how long RenderBothAndDiff waits for the GPU
+// path to come up, which can take longer than a regular Render allows
+const parityStartTimeout = 5 * time.Second
+
+// RenderBothAndDiff renders state once through the GPU path and once through
+// the software path at the same tick and reports how far apart they are.
+// diff is the mean absolute per-channel difference scaled to [0, 1], where 0
+// means identical output. If the GPU path times out and Render falls back
+// to software, RenderBothAndDiff returns an error rather than a diff of 0.
+func RenderBothAndDiff(opts Options, state State) (gpu, sw image.Image, diff float64, err error) {
+	opts.UseGPU = true
+	r, err := New(opts)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer r.Close()
+
+	r.Update(state)
+	if !r.waitForGame(parityStartTimeout) {
+		return nil, nil, 0, errors.New("gpu renderer failed to start")
+	}
+
+	// Render drops frames captured before the state was set
+	gpu, ok := r.render(state)
+	if gpu == nil {
+		return nil, nil, 0, errors.New("gpu render produced no frame")
+	}
+	if !ok {
+		return nil, nil, 0, errors.New("gpu render timed out and fell back to software")
+	}
+	sw = r.renderFrameSoftware(state)
+
+	diff, err = imageDiff(gpu, sw)
+	return gpu, sw, diff, err
+}
+
+// imageDiff returns the mean absolute per-channel difference between two
+// images of the same size, scaled to [0, 1].
+func imageDiff(a, b image.Image) (float64, error) {
+	ab, bb := a.Bounds(), b.Bounds()
+	if ab.Dx() != bb.Dx() || ab.Dy() != bb.Dy() {
+		return 0, fmt.Errorf("image sizes differ: %v vs %v", ab.Size(), bb.Size())
+	}
+	if ab.Empty() {
+		return 0, nil
+	}
+
+	var total uint64
+	for y := 0; y < ab.Dy(); y++ {
+		for x := 0; x < ab.Dx(); x++ {
+			r1, g1, b1, a1 := a.At(ab.Min.X+x, ab.Min.Y+y).RGBA()
+			r2, g2, b2, a2 := b.At(bb.Min.X+x, bb.Min.Y+y).RGBA()
+			total += absDiff(r1, r2) + absDiff(g1, g2) + absDiff(b1, b2) + absDiff(a1, a2)
+		}
+	}
+
+	channels := uint64(ab.Dx()) * uint64(ab.Dy()) * 4
+	return float64(total) / float64(channels) / 0xffff, nil
+}
+
+func absDiff(a, b uint32) uint64 {
+	if a > b {
+		return uint64(a - b)
+	}
+	return uint64(b - a)
+}
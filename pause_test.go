@@ -0,0 +1,87 @@
+package nimsforestsprites
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPauseStopsTickInFrames(t *testing.T) {
+	clock := newFakeClock()
+	r := newTestRenderer(t, Options{FrameRate: 30, Clock: clock})
+	r.Update(NewMockStateSeed(1))
+
+	// Paused before Frames starts
+	r.Pause()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	frames := r.FramesWithInfo(ctx)
+	var ticker *fakeTicker
+	select {
+	case ticker = <-clock.tickers:
+	case <-time.After(time.Second):
+		t.Fatal("FramesWithInfo never created a ticker")
+	}
+	next := func() Frame {
+		t.Helper()
+		clock.fire(ticker)
+		select {
+		case f := <-frames:
+			return f
+		case <-time.After(time.Second):
+			t.Fatal("no frame after a tick")
+			return Frame{}
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if f := next(); f.Tick != 0 || f.Image == nil {
+			t.Fatalf("paused frame %d has tick %d and image %v, want tick 0 and an image", i, f.Tick, f.Image)
+		}
+	}
+
+	r.Resume()
+	for want := 1; want <= 2; want++ {
+		if f := next(); f.Tick != want {
+			t.Fatalf("frame after Resume has tick %d, want %d", f.Tick, want)
+		}
+	}
+}
+
+func TestSkipUnchangedEmitsOnceWhilePaused(t *testing.T) {
+	clock := newFakeClock()
+	r := newTestRenderer(t, Options{FrameRate: 30, Clock: clock, SkipUnchanged: true})
+	r.Update(NewMockStateSeed(1))
+	r.Pause()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	frames := r.Frames(ctx)
+	ticker := <-clock.tickers
+
+	// Each fire waits for the loop to take the tick, so after the last one
+	// every earlier tick has been handled
+	for i := 0; i < 5; i++ {
+		clock.fire(ticker)
+	}
+	select {
+	case <-frames:
+	case <-time.After(time.Second):
+		t.Fatal("the first frame was not emitted")
+	}
+	clock.fire(ticker)
+	select {
+	case <-frames:
+		t.Fatal("an unchanged paused frame was emitted twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Changing the state emits again
+	r.Update(NewMockStateSeed(2))
+	clock.fire(ticker)
+	select {
+	case <-frames:
+	case <-time.After(time.Second):
+		t.Fatal("no frame after the state changed")
+	}
+}
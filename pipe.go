@@ -0,0 +1,201 @@
+package nimsforestsprites
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// pipeHeaderSize is the length of the header in front of every frame
+const pipeHeaderSize = 20
+
+// PipeOptions configures a PipeSink
+type PipeOptions struct {
+	// Backpressure controls what WriteFrame does when the peer reads
+	// slower than frames arrive (default DropNewest)
+	Backpressure Backpressure
+
+	// Buffer is how many frames may be queued for the peer (default 2)
+	Buffer int
+}
+
+// PipeSink streams raw frames to another local process over a Unix domain
+// socket. It listens at path and serves one peer at a time; when the peer
+// disconnects, a new one can connect.
+//
+// Each frame is written as a big-endian header followed by the pixels:
+//
+//	uint32 length  // bytes after this field: 16 + width*height*4
+//	uint32 width
+//	uint32 height
+//	uint64 tick
+//	[]byte pixels  // premultiplied RGBA, row-major, no padding
+type PipeSink struct {
+	opts     PipeOptions
+	listener net.Listener
+	frames   chan []byte
+	done     chan struct{}
+	wg       sync.WaitGroup
+
+	mu     sync.Mutex
+	conn   net.Conn
+	err    error // Why the last peer was dropped, reported once by WriteFrame
+	closed bool
+
+	dropped atomic.Int64
+}
+
+// NewPipeSink listens for a peer on the Unix socket at path
+func NewPipeSink(path string, opts PipeOptions) (*PipeSink, error) {
+	if opts.Buffer == 0 {
+		opts.Buffer = 2
+	}
+	if opts.Buffer < 0 {
+		return nil, fmt.Errorf("pipe buffer %d is negative", opts.Buffer)
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &PipeSink{
+		opts:     opts,
+		listener: ln,
+		frames:   make(chan []byte, opts.Buffer),
+		done:     make(chan struct{}),
+	}
+	s.wg.Add(2)
+	go s.acceptLoop()
+	go s.writeLoop()
+	return s, nil
+}
+
+// WriteFrame queues a frame for the peer without waiting for it to be
+// written, unless Backpressure is Block. Frames are dropped while no peer
+// is connected. If the previous peer disconnected, WriteFrame returns that
+// error once and the sink waits for a new peer.
+func (s *PipeSink) WriteFrame(img image.Image, tick int) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errors.New("pipe sink is closed")
+	}
+	if err := s.err; err != nil {
+		s.err = nil
+		s.mu.Unlock()
+		return fmt.Errorf("pipe peer disconnected: %w", err)
+	}
+	connected := s.conn != nil
+	s.mu.Unlock()
+
+	if !connected {
+		s.dropped.Add(1)
+		return nil
+	}
+
+	n := sendFrame(s.frames, encodePipeFrame(img, tick), s.opts.Backpressure, s.done, nil)
+	s.dropped.Add(int64(n))
+	return nil
+}
+
+// Dropped returns how many frames were discarded because no peer was
+// connected or the peer was too slow
+func (s *PipeSink) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Close stops listening, disconnects the peer, and removes the socket
+func (s *PipeSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	conn := s.conn
+	s.conn = nil
+	s.mu.Unlock()
+
+	err := s.listener.Close()
+	if conn != nil {
+		conn.Close()
+	}
+	s.wg.Wait()
+	return err
+}
+
+func (s *PipeSink) acceptLoop() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.closed || s.conn != nil {
+			// Only one peer at a time
+			s.mu.Unlock()
+			conn.Close()
+			continue
+		}
+		s.conn = conn
+		s.mu.Unlock()
+	}
+}
+
+func (s *PipeSink) writeLoop() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.done:
+			return
+		case frame := <-s.frames:
+			s.mu.Lock()
+			conn := s.conn
+			s.mu.Unlock()
+			if conn == nil {
+				s.dropped.Add(1)
+				continue
+			}
+
+			if _, err := conn.Write(frame); err != nil {
+				s.mu.Lock()
+				if s.conn == conn {
+					s.conn = nil
+					if !s.closed {
+						s.err = err
+					}
+				}
+				s.mu.Unlock()
+				conn.Close()
+
+				// Queued frames were meant for the old peer
+				s.dropped.Add(int64(drainFrames(s.frames)))
+			}
+		}
+	}
+}
+
+// encodePipeFrame returns img with its header in the PipeSink wire format
+func encodePipeFrame(img image.Image, tick int) []byte {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	buf := make([]byte, pipeHeaderSize+4*w*h)
+
+	binary.BigEndian.PutUint32(buf[0:], uint32(len(buf)-4))
+	binary.BigEndian.PutUint32(buf[4:], uint32(w))
+	binary.BigEndian.PutUint32(buf[8:], uint32(h))
+	binary.BigEndian.PutUint64(buf[12:], uint64(tick))
+
+	dst := &image.RGBA{Pix: buf[pipeHeaderSize:], Stride: 4 * w, Rect: image.Rect(0, 0, w, h)}
+	draw.Draw(dst, dst.Rect, img, b.Min, draw.Src)
+	return buf
+}
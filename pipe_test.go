@@ -0,0 +1,130 @@
+package nimsforestsprites
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestEncodePipeFrameHeader(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	img.SetRGBA(2, 1, color.RGBA{1, 2, 3, 255})
+	buf := encodePipeFrame(img, 42)
+
+	be := binary.BigEndian
+	if n := be.Uint32(buf); int(n) != len(buf)-4 || n != 16+3*2*4 {
+		t.Errorf("length field is %d, want %d", n, 16+3*2*4)
+	}
+	if w, h, tick := be.Uint32(buf[4:]), be.Uint32(buf[8:]), be.Uint64(buf[12:]); w != 3 || h != 2 || tick != 42 {
+		t.Errorf("header is %dx%d at tick %d, want 3x2 at tick 42", w, h, tick)
+	}
+	if px := buf[len(buf)-4:]; px[0] != 1 || px[1] != 2 || px[2] != 3 || px[3] != 255 {
+		t.Errorf("last pixel is %v, want 1 2 3 255", px)
+	}
+}
+
+// readPipeFrame reads one frame from a PipeSink peer connection
+func readPipeFrame(t *testing.T, conn net.Conn) (w, h, tick int, pix []byte) {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var size [4]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		t.Fatal(err)
+	}
+	body := make([]byte, binary.BigEndian.Uint32(size[:]))
+	if _, err := io.ReadFull(conn, body); err != nil {
+		t.Fatal(err)
+	}
+	be := binary.BigEndian
+	return int(be.Uint32(body)), int(be.Uint32(body[4:])), int(be.Uint64(body[8:])), body[16:]
+}
+
+// newTestPipeSink starts a PipeSink that blocks for slow peers, and
+// returns it with a function that connects a peer
+func newTestPipeSink(t *testing.T) (*PipeSink, func() net.Conn) {
+	t.Helper()
+	// Socket paths are limited to about 100 bytes, so keep it short
+	dir, err := os.MkdirTemp("", "pipe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "s")
+
+	sink, err := NewPipeSink(path, PipeOptions{Backpressure: Block})
+	if err != nil {
+		t.Skipf("cannot listen on a Unix socket: %v", err)
+	}
+	t.Cleanup(func() { sink.Close() })
+
+	connect := func() net.Conn {
+		t.Helper()
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		for deadline := time.Now().Add(time.Second); ; time.Sleep(time.Millisecond) {
+			sink.mu.Lock()
+			connected := sink.conn != nil
+			sink.mu.Unlock()
+			if connected {
+				return conn
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("sink never accepted the peer")
+			}
+		}
+	}
+	return sink, connect
+}
+
+func TestPipeSinkStreamsFrames(t *testing.T) {
+	sink, connect := newTestPipeSink(t)
+	conn := connect()
+
+	frame := image.NewRGBA(image.Rect(0, 0, 8, 6))
+	for tick := 1; tick <= 3; tick++ {
+		if err := sink.WriteFrame(frame, tick); err != nil {
+			t.Fatal(err)
+		}
+		if w, h, got, pix := readPipeFrame(t, conn); w != 8 || h != 6 || got != tick || len(pix) != 8*6*4 {
+			t.Fatalf("read %dx%d at tick %d with %d bytes, want 8x6 at tick %d with %d", w, h, got, len(pix), tick, 8*6*4)
+		}
+	}
+}
+
+func TestPipeSinkReportsDisconnectAndReconnects(t *testing.T) {
+	if runtime.GOOS == "js" {
+		t.Skip("js's in-memory network does not report hangups to the writer")
+	}
+	sink, connect := newTestPipeSink(t)
+	conn := connect()
+	frame := image.NewRGBA(image.Rect(0, 0, 8, 6))
+
+	// Writes to a peer that hung up fail, and WriteFrame reports it once
+	conn.Close()
+	var disconnected error
+	for i := 0; i < 100 && disconnected == nil; i++ {
+		disconnected = sink.WriteFrame(frame, i)
+		time.Sleep(time.Millisecond)
+	}
+	if disconnected == nil {
+		t.Fatal("WriteFrame never reported the disconnect")
+	}
+
+	conn = connect()
+	if err := sink.WriteFrame(frame, 200); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, tick, _ := readPipeFrame(t, conn); tick != 200 {
+		t.Errorf("reconnected peer read tick %d, want 200", tick)
+	}
+}
@@ -0,0 +1,31 @@
+package nimsforestsprites
+
+import "time"
+
+// stateFetcher calls Options.StateProvider for the Frames loop, with at
+// most one call running at a time
+type stateFetcher struct {
+	provider func() State
+	pending  chan State // Result of a call still running, nil when idle
+}
+
+// fetch returns the provider's state, waiting at most budget for it. ok is
+// false when the call is still running; a later fetch picks up its result
+// instead of starting another.
+func (f *stateFetcher) fetch(clock Clock, budget time.Duration) (state State, ok bool) {
+	if f.pending == nil {
+		ch := make(chan State, 1)
+		go func() { ch <- f.provider() }()
+		f.pending = ch
+	}
+
+	timeout := clock.NewTicker(budget)
+	defer timeout.Stop()
+	select {
+	case state = <-f.pending:
+		f.pending = nil
+		return state, true
+	case <-timeout.C():
+		return nil, false
+	}
+}
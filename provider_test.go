@@ -0,0 +1,56 @@
+package nimsforestsprites
+
+import (
+	"context"
+	"image"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStateProviderPulledEachFrame(t *testing.T) {
+	var calls atomic.Int32
+	provider := func() State {
+		n := calls.Add(1)
+		if n == 4 {
+			return nil
+		}
+		return &staticState{processes: []Process{{ID: "p", Type: "tree", X: float64(n - 1)}}}
+	}
+	clock := newFakeClock()
+	r := newTestRenderer(t, Options{Width: 400, Height: 240, Clock: clock, StateProvider: provider})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	frames := r.Frames(ctx)
+	ticker := <-clock.tickers
+	// The provider's time budget takes a ticker each frame, which never
+	// fires since the provider returns at once
+	go func() {
+		for {
+			select {
+			case <-clock.tickers:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	tree, bg := DefaultPalette().ProcessColor("tree"), DefaultPalette().Background
+	for i := 0; i < 3; i++ {
+		clock.fire(ticker)
+		img := (<-frames).(*image.RGBA)
+		if got := img.RGBAAt(132+64*i, 132); got != tree {
+			t.Fatalf("frame %d: pixel at column %d is %v, want the process from call %d", i+1, i, got, i+1)
+		}
+	}
+
+	// A nil state draws just the background
+	clock.fire(ticker)
+	img := (<-frames).(*image.RGBA)
+	if got := img.RGBAAt(132+64*2, 132); got != bg {
+		t.Errorf("pixel after the provider returned nil is %v, want the background", got)
+	}
+	if n := calls.Load(); n != 4 {
+		t.Errorf("provider called %d times for 4 frames", n)
+	}
+}
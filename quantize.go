@@ -0,0 +1,77 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"image/color"
+)
+
+// quantBits is the precision per channel of the output palette lookup
+const quantBits = 5
+
+// outputQuantizer snaps frames to a fixed palette through a lookup table
+// indexed by the top quantBits of each channel, built once so frames can
+// be quantized concurrently without locking
+type outputQuantizer struct {
+	palette color.Palette
+	colors  []color.RGBA // palette as premultiplied RGBA
+	lut     []uint16     // Palette index for each quantized RGB
+}
+
+// SetOutputPalette snaps every frame's pixels to the nearest color of p
+// after filters run, for retro output and small GIFs. Pass the same
+// palette to GIFOptions.Palette so the encoder keeps the colors exact.
+// A nil or empty palette turns quantization off.
+func (r *Renderer) SetOutputPalette(p color.Palette) {
+	var q *outputQuantizer
+	if len(p) > 0 {
+		q = newOutputQuantizer(p)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.quantizer = q
+}
+
+// OutputPalette returns the palette set with SetOutputPalette, or nil
+func (r *Renderer) OutputPalette() color.Palette {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.quantizer == nil {
+		return nil
+	}
+	return append(color.Palette(nil), r.quantizer.palette...)
+}
+
+func newOutputQuantizer(p color.Palette) *outputQuantizer {
+	q := &outputQuantizer{
+		palette: append(color.Palette(nil), p...),
+		colors:  make([]color.RGBA, len(p)),
+		lut:     make([]uint16, 1<<(3*quantBits)),
+	}
+	for i, c := range p {
+		q.colors[i] = color.RGBAModel.Convert(c).(color.RGBA)
+	}
+
+	// Each entry maps the center of its cell of the RGB cube
+	const step = 1 << (8 - quantBits)
+	for i := range q.lut {
+		c := color.RGBA{
+			R: uint8(i>>(2*quantBits))<<(8-quantBits) + step/2,
+			G: uint8(i>>quantBits&(1<<quantBits-1))<<(8-quantBits) + step/2,
+			B: uint8(i&(1<<quantBits-1))<<(8-quantBits) + step/2,
+			A: 255,
+		}
+		q.lut[i] = uint16(q.palette.Index(c))
+	}
+	return q
+}
+
+// quantize replaces every pixel of img with its palette color. Frames sit
+// on an opaque background, so alpha is ignored like in EncodeGIF.
+func (q *outputQuantizer) quantize(img *image.RGBA) {
+	eachPixel(img, func(p []uint8) {
+		i := int(p[0]>>(8-quantBits))<<(2*quantBits) | int(p[1]>>(8-quantBits))<<quantBits | int(p[2]>>(8-quantBits))
+		c := q.colors[q.lut[i]]
+		p[0], p[1], p[2], p[3] = c.R, c.G, c.B, c.A
+	})
+}
@@ -0,0 +1,29 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestOutputPaletteTwoColors(t *testing.T) {
+	dark := color.RGBA{10, 20, 30, 255}
+	light := color.RGBA{220, 230, 200, 255}
+	r := newTestRenderer(t, Options{})
+	r.SetOutputPalette(color.Palette{dark, light})
+
+	img := r.RenderAt(NewMockStateSeed(4), 3).(*image.RGBA)
+	seen := make(map[color.RGBA]bool)
+	for y := 0; y < img.Rect.Dy(); y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			c := img.RGBAAt(x, y)
+			if c != dark && c != light {
+				t.Fatalf("pixel (%d, %d) is %v, outside the palette", x, y, c)
+			}
+			seen[c] = true
+		}
+	}
+	if len(seen) != 2 {
+		t.Fatalf("only %d of the 2 palette colors used", len(seen))
+	}
+}
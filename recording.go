@@ -0,0 +1,199 @@
+package nimsforestsprites
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RecordedState is a snapshot of a State captured by a Recorder
+type RecordedState struct {
+	At        time.Duration `json:"at"` // Time since the recording started
+	Lands     []Land        `json:"lands"`
+	Processes []Process     `json:"processes"`
+}
+
+// State returns the snapshot as a renderable State
+func (s RecordedState) State() State {
+	return &staticState{lands: s.Lands, processes: s.Processes}
+}
+
+// Recording is a sequence of recorded states in time order
+type Recording []RecordedState
+
+// FrameAt returns the state that was showing at t into the recording: the
+// last one recorded at or before t. Times before the first state return
+// the first, and times after the last return the last. An empty recording
+// returns nil, which renders as the background. Together with RenderAt
+// this lets a UI scrub through a recording.
+func (rec Recording) FrameAt(t time.Duration) State {
+	i := rec.activeAt(t)
+	if i < 0 {
+		return nil
+	}
+	return rec[i].State()
+}
+
+// BlendAt is like FrameAt, but moves processes part of the way toward
+// where the next recorded state has them, in proportion to how far t is
+// between the two, so scrubbing shows smooth motion. Unlike
+// Options.Interpolate it does not fade: processes that only exist in the
+// next state appear when it does, and ones missing from it vanish then.
+// Edges are not recorded, so neither FrameAt nor BlendAt draws any.
+func (rec Recording) BlendAt(t time.Duration) State {
+	i := rec.activeAt(t)
+	if i < 0 {
+		return nil
+	}
+	if i+1 == len(rec) || t <= rec[i].At {
+		return rec[i].State()
+	}
+
+	curr, next := rec[i], rec[i+1]
+	frac := 1.0
+	if span := next.At - curr.At; span > 0 {
+		frac = float64(t-curr.At) / float64(span)
+	}
+
+	nextByID := make(map[string]Process, len(next.Processes))
+	for _, p := range next.Processes {
+		nextByID[p.ID] = p
+	}
+	processes := make([]Process, len(curr.Processes))
+	for j, p := range curr.Processes {
+		if to, ok := nextByID[p.ID]; ok {
+			p.X = lerp(p.X, to.X, frac)
+			p.Y = lerp(p.Y, to.Y, frac)
+			if to.Progress >= p.Progress {
+				// Progress wraps from 1 back to 0; don't animate it backwards
+				p.Progress = lerp(p.Progress, to.Progress, frac)
+			}
+		}
+		processes[j] = p
+	}
+	return &staticState{lands: curr.Lands, processes: processes}
+}
+
+// activeAt returns the index of the state showing at t, clamped to the
+// recording, or -1 if it is empty
+func (rec Recording) activeAt(t time.Duration) int {
+	if len(rec) == 0 {
+		return -1
+	}
+	i := sort.Search(len(rec), func(i int) bool { return rec[i].At > t })
+	return max(0, i-1)
+}
+
+// Recorder wraps Renderer.Update, snapshotting every state it passes on so
+// the exact sequence can be saved and replayed later.
+type Recorder struct {
+	renderer *Renderer
+
+	mu     sync.Mutex
+	start  time.Time
+	states Recording
+}
+
+// NewRecorder creates a recorder that forwards updates to r
+func NewRecorder(r *Renderer) *Recorder {
+	return &Recorder{renderer: r}
+}
+
+// Update snapshots state and passes it on to the renderer
+func (rec *Recorder) Update(state State) {
+	rec.mu.Lock()
+	now := rec.renderer.opts.Clock.Now()
+	if rec.start.IsZero() {
+		rec.start = now
+	}
+	snap := RecordedState{At: now.Sub(rec.start)}
+	if state != nil {
+		snap.Lands = state.Lands()
+		snap.Processes = state.Processes()
+	}
+	rec.states = append(rec.states, snap)
+	rec.mu.Unlock()
+
+	rec.renderer.Update(state)
+}
+
+// Recording returns a copy of everything recorded so far
+func (rec *Recorder) Recording() Recording {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	result := make(Recording, len(rec.states))
+	copy(result, rec.states)
+	return result
+}
+
+// SaveRecording writes the recording to w as JSON
+func (rec *Recorder) SaveRecording(w io.Writer) error {
+	return json.NewEncoder(w).Encode(rec.Recording())
+}
+
+// ReplayRecording reads a recording written by SaveRecording
+func ReplayRecording(r io.Reader) (Recording, error) {
+	var rec Recording
+	if err := json.NewDecoder(r).Decode(&rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// PlayRecording drives Update with each recorded state at its recorded
+// time, checked once per frame on Options.Clock. When several states fall
+// due within one frame interval only the last of them is applied, since
+// the others would never be drawn. It blocks until playback finishes or
+// ctx is cancelled.
+func (r *Renderer) PlayRecording(ctx context.Context, rec Recording) error {
+	if len(rec) == 0 {
+		return nil
+	}
+
+	ticker := r.opts.Clock.NewTicker(r.frameDuration())
+	defer ticker.Stop()
+
+	start := r.opts.Clock.Now()
+	now := start
+	applied := 0 // States due so far, the last of which was applied
+	for {
+		due := sort.Search(len(rec), func(i int) bool { return rec[i].At > now.Sub(start) })
+		if due > applied {
+			r.Update(rec[due-1].State())
+			applied = due
+		}
+		if applied == len(rec) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now = <-ticker.C():
+		}
+	}
+}
+
+// staticState is a fixed State backed by plain slices
+type staticState struct {
+	lands     []Land
+	processes []Process
+}
+
+// Lands returns a copy of the lands
+func (s *staticState) Lands() []Land {
+	result := make([]Land, len(s.lands))
+	copy(result, s.lands)
+	return result
+}
+
+// Processes returns a copy of the processes
+func (s *staticState) Processes() []Process {
+	result := make([]Process, len(s.processes))
+	copy(result, s.processes)
+	return result
+}
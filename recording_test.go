@@ -0,0 +1,114 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func threeStateRecording() Recording {
+	state := func(at time.Duration, id string, x float64) RecordedState {
+		return RecordedState{
+			At:        at,
+			Lands:     []Land{{ID: id}},
+			Processes: []Process{{ID: "p", LandID: id, X: x}},
+		}
+	}
+	return Recording{
+		state(0, "first", 0),
+		state(time.Second, "second", 2),
+		state(2*time.Second, "third", 4),
+	}
+}
+
+func TestRecordingFrameAt(t *testing.T) {
+	rec := threeStateRecording()
+	tests := []struct {
+		at   time.Duration
+		want string
+	}{
+		{-time.Second, "first"}, // Clamped to the start
+		{0, "first"},
+		{500 * time.Millisecond, "first"},
+		{time.Second, "second"},
+		{1999 * time.Millisecond, "second"},
+		{2 * time.Second, "third"},
+		{time.Minute, "third"}, // Clamped to the end
+	}
+	for _, tt := range tests {
+		if got := rec.FrameAt(tt.at).Lands()[0].ID; got != tt.want {
+			t.Errorf("FrameAt(%v) = %s, want %s", tt.at, got, tt.want)
+		}
+	}
+
+	if Recording(nil).FrameAt(time.Second) != nil {
+		t.Error("FrameAt on an empty recording should be nil")
+	}
+}
+
+func TestRecordingBlendAt(t *testing.T) {
+	rec := threeStateRecording()
+	if x := rec.BlendAt(1500 * time.Millisecond).Processes()[0].X; x != 3 {
+		t.Fatalf("BlendAt halfway between states has X = %v, want 3", x)
+	}
+	if x := rec.BlendAt(time.Minute).Processes()[0].X; x != 4 {
+		t.Fatalf("BlendAt past the end has X = %v, want 4", x)
+	}
+}
+
+func TestRecordingRoundTrip(t *testing.T) {
+	clock := newFakeClock()
+	r := newTestRenderer(t, Options{FrameRate: 10, Clock: clock})
+	recorder := NewRecorder(r)
+
+	state := NewMockStateSeed(5)
+	recorder.Update(state)
+	for i := 0; i < 3; i++ {
+		clock.advance(250 * time.Millisecond)
+		state.Randomize()
+		recorder.Update(state)
+	}
+	clock.advance(time.Second)
+	recorder.Update(nil)
+
+	var buf bytes.Buffer
+	if err := recorder.SaveRecording(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := ReplayRecording(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := recorder.Recording()
+	if !reflect.DeepEqual(loaded, want) {
+		t.Fatalf("loaded recording differs from the one saved:\n got %+v\nwant %+v", loaded, want)
+	}
+	if loaded[1].At != 250*time.Millisecond {
+		t.Fatalf("second state recorded at %v, want 250ms", loaded[1].At)
+	}
+
+	// Replaying it ends on the last recorded state
+	player := newTestRenderer(t, Options{FrameRate: 10, Clock: clock})
+	done := make(chan error, 1)
+	go func() { done <- player.PlayRecording(context.Background(), loaded[:4]) }()
+	ticker := <-clock.tickers
+	for {
+		clock.advance(ticker.d)
+		select {
+		case ticker.c <- clock.Now():
+		case err := <-done:
+			if err != nil {
+				t.Fatal(err)
+			}
+			player.mu.RLock()
+			got := player.state.Processes()
+			player.mu.RUnlock()
+			if !reflect.DeepEqual(got, loaded[3].Processes) {
+				t.Fatalf("playback ended on %+v, want %+v", got, loaded[3].Processes)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"image/draw"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// regionRequest asks the ebiten game to read part of its offscreen image
+type regionRequest struct {
+	rect  image.Rectangle
+	reply chan *image.RGBA // Buffered, so the game never waits on it
+}
+
+// CaptureRegion returns a copy of the part of the most recent frame inside
+// rect, clipped to the frame. While the GPU game is running, only that
+// region of the offscreen image is read back from the GPU, on its next
+// frame; otherwise it is cropped from the last frame Render or Frames
+// produced. Filters and the output palette apply to the region as they do
+// to full frames. ok is false when rect lies entirely outside the frame or
+// no frame is available.
+func (r *Renderer) CaptureRegion(rect image.Rectangle) (img image.Image, ok bool) {
+	rect = rect.Intersect(image.Rect(0, 0, r.opts.Width, r.opts.Height))
+	if rect.Empty() {
+		return nil, false
+	}
+
+	if r.opts.UseGPU && r.gameRunning() {
+		req := regionRequest{rect: rect, reply: make(chan *image.RGBA, 1)}
+		timeout := time.After(100 * time.Millisecond)
+		select {
+		case r.regionCh <- req:
+			select {
+			case region := <-req.reply:
+				return region, true
+			case <-timeout:
+			case <-r.done:
+				return nil, false
+			}
+		case <-timeout:
+		case <-r.done:
+			return nil, false
+		}
+		// The game is busy; fall back to the last captured frame
+	}
+
+	r.mu.RLock()
+	last := r.lastFrame
+	r.mu.RUnlock()
+	if last == nil {
+		return nil, false
+	}
+	region := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(region, region.Bounds(), last, last.Bounds().Min.Add(rect.Min), draw.Src)
+	return region, true
+}
+
+// gameRunning reports whether the ebiten game has started drawing, without
+// starting it
+func (r *Renderer) gameRunning() bool {
+	r.mu.RLock()
+	done := r.gameDone
+	r.mu.RUnlock()
+	if done == nil {
+		return false
+	}
+
+	select {
+	case <-done:
+		return false
+	case <-r.gameReady:
+		return true
+	default:
+		return false
+	}
+}
+
+// serveRegions answers the CaptureRegion requests waiting on the game,
+// reading just the requested part of the offscreen image
+func (g *ebitenGame) serveRegions(style drawStyle) {
+	frame := g.offscreen.Bounds()
+	for {
+		select {
+		case req := <-g.renderer.regionCh:
+			req.reply <- finishRegion(req.rect, frame, style, func(rect image.Rectangle, pix []uint8) {
+				g.offscreen.SubImage(rect).(*ebiten.Image).ReadPixels(pix)
+			})
+		default:
+			return
+		}
+	}
+}
+
+// finishRegion reads rect of an unfiltered frame with read, which fills
+// pix with the pixels of the rectangle it is given, and filters it the way
+// the whole frame would be. Filters see the region at its place in the
+// frame, with a margin as wide as Bloom reaches so glow from just outside
+// still spills in; a custom filter that spreads pixels further may differ
+// near the region's edges.
+func finishRegion(rect, frame image.Rectangle, style drawStyle, read func(rect image.Rectangle, pix []uint8)) *image.RGBA {
+	src := rect
+	if len(style.filters) > 0 {
+		src = rect.Inset(-bloomRadius).Intersect(frame)
+	}
+	buf := image.NewRGBA(src)
+	read(src, buf.Pix)
+	finishFrame(buf, style)
+	if src == rect {
+		buf.Rect = image.Rect(0, 0, rect.Dx(), rect.Dy())
+		return buf
+	}
+
+	region := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(region, region.Bounds(), buf, rect.Min, draw.Src)
+	return region
+}
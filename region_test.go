@@ -0,0 +1,61 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"testing"
+)
+
+// crop copies rect out of img into a new image at the origin
+func crop(img image.Image, rect image.Rectangle) *image.RGBA {
+	out := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(out, out.Bounds(), img, rect.Min, draw.Src)
+	return out
+}
+
+func TestCaptureRegionMatchesCrop(t *testing.T) {
+	r := newTestRenderer(t, Options{Width: 400, Height: 300})
+	r.AddFilter(Scanlines)
+	r.AddFilter(Bloom)
+	frame := r.Render(NewMockStateSeed(3))
+
+	rect := image.Rect(117, 91, 245, 203)
+	region, ok := r.CaptureRegion(rect)
+	if !ok {
+		t.Fatal("CaptureRegion found no frame")
+	}
+	if !bytes.Equal(region.(*image.RGBA).Pix, crop(frame, rect).Pix) {
+		t.Error("CaptureRegion differs from a crop of the rendered frame")
+	}
+}
+
+// The GPU path filters regions read back from its unfiltered offscreen
+// image; check that against filtering the whole frame
+func TestFinishRegionMatchesFilteredFrame(t *testing.T) {
+	r := newTestRenderer(t, Options{Width: 400, Height: 300})
+	r.AddFilter(Scanlines)
+	r.AddFilter(Bloom)
+	r.mu.RLock()
+	style := r.styleLocked()
+	r.mu.RUnlock()
+
+	raw := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	r.drawSceneSW(raw, NewMockStateSeed(3), 0, style)
+	full := crop(raw, raw.Bounds())
+	finishFrame(full, style)
+	read := func(rect image.Rectangle, pix []uint8) {
+		copy(pix, crop(raw, rect).Pix)
+	}
+
+	for _, rect := range []image.Rectangle{
+		image.Rect(117, 91, 245, 203), // Odd offset, glow from outside
+		image.Rect(0, 0, 50, 40),      // Against the frame's corner
+		image.Rect(300, 251, 400, 300),
+	} {
+		got := finishRegion(rect, raw.Bounds(), style, read)
+		if !bytes.Equal(got.Pix, crop(full, rect).Pix) {
+			t.Errorf("region %v differs from the filtered frame", rect)
+		}
+	}
+}
@@ -1,16 +1,55 @@
 package nimsforestsprites
 
 import (
+	"cmp"
 	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"image"
 	"image/color"
 	"image/draw"
+	"math"
+	"os"
+	"runtime"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
+// ErrRendererClosed is returned when rendering with a closed renderer
+var ErrRendererClosed = errors.New("renderer closed")
+
+// ErrInvalidOptions is wrapped by the error New returns for out-of-range
+// options
+var ErrInvalidOptions = errors.New("invalid options")
+
+const (
+	// animationRate is how many animation steps make up one second of the
+	// animation clock, the rate the pulse and bounce curves were tuned at
+	animationRate = 30
+
+	// maxAnimationStep caps a single advance of the animation clock
+	maxAnimationStep = 250 * time.Millisecond
+)
+
+// animationPhase converts animation clock time to animation steps
+func animationPhase(elapsed time.Duration) float64 {
+	return elapsed.Seconds() * animationRate
+}
+
+// processBounce returns the vertical offset, in pixels, of a process sprite
+// at grid column x. Neighboring columns bob out of step.
+func processBounce(phase, x float64) float64 {
+	return math.Sin(phase/10.0+x*0.5) * 3
+}
+
 // Options configures the renderer
 type Options struct {
 	Width     int     // Frame width (default 1920)
@@ -18,6 +57,73 @@ type Options struct {
 	FrameRate int     // Target FPS (default 30)
 	Scale     float64 // Sprite scale (default 1.0)
 	UseGPU    bool    // Use GPU rendering via ebiten (default true)
+
+	// AdaptiveScale lowers Scale a notch whenever the frame rate stays
+	// below 80% of FrameRate for a full second
+	AdaptiveScale bool
+
+	// Palette sets the background and type colors (default DefaultPalette)
+	Palette *Palette
+
+	// DoubleBuffer makes GPU capture read back into two preallocated
+	// buffers instead of allocating a new image in the draw loop. Frames
+	// are copied out of a buffer before they are handed out, and a buffer
+	// is reused only once its frame has been copied or discarded.
+	DoubleBuffer bool
+
+	// RenderWorkers is the number of goroutines the software path uses to
+	// draw land tiles (default 0 = one per CPU)
+	RenderWorkers int
+
+	// Interpolate blends process positions and progress between successive
+	// updates, matching processes by ID, so motion stays smooth when state
+	// updates arrive slower than the frame rate
+	Interpolate bool
+
+	// Backpressure controls what happens when a frame channel is full,
+	// both for GPU capture and for the Frames output (default DropNewest)
+	Backpressure Backpressure
+
+	// FrameBuffer is the capacity of the GPU capture channel and of the
+	// channels returned by Frames and FramesWithInfo (default 2)
+	FrameBuffer int
+
+	// Clock supplies time to the Frames loop, interpolation, and render
+	// timing (default nil = the wall clock)
+	Clock Clock
+
+	// SkipUnchanged makes Frames and FramesWithInfo emit a frame only when
+	// its drawing inputs differ from the last emitted one, which saves
+	// downstream encoding while the scene is paused or static. The first
+	// frame is always emitted.
+	SkipUnchanged bool
+
+	// SnapshotSignal, when set, saves the most recent frame as a
+	// timestamped PNG in SnapshotDir (default ".") whenever the process
+	// receives this signal, e.g. syscall.SIGUSR1
+	SnapshotSignal os.Signal
+	SnapshotDir    string
+
+	// StateProvider, when set, is called by the Frames loop before each
+	// frame to pull the state to draw, replacing whatever Update set. The
+	// loop waits at most half a frame interval for it, leaving the rest
+	// for drawing; a slower call keeps the previous state on screen until
+	// it returns.
+	StateProvider func() State
+
+	// CatchUp makes the Frames loop emit extra frames, stamped with the
+	// times they were due, after a stall made it miss some, so a recording
+	// keeps FrameRate frames per second of wall-clock time. At most 10
+	// extra frames are emitted per tick. Stats reports the drift either
+	// way.
+	CatchUp bool
+
+	// Manual runs the renderer without goroutines, tickers, or sleeps, for
+	// single-threaded hosts such as WebAssembly in a browser. The caller
+	// produces each frame with Step or Render; rendering is software only
+	// and on the calling goroutine, UseGPU and RenderWorkers are ignored,
+	// and Frames and FramesWithInfo return closed channels.
+	Manual bool
 }
 
 // DefaultOptions returns the default renderer options
@@ -37,12 +143,73 @@ type Renderer struct {
 	state  State
 	mu     sync.RWMutex
 	closed bool
+	done   chan struct{} // Closed by Close to release blocked senders
 	tick   int
-
-	// For GPU mode: ebiten game running in background
+	paused bool // Frames keeps emitting but stops advancing tick
+
+	// elapsed is the animation clock. Pulse, bounce, and status animations
+	// follow it, so their speed does not depend on the frame rate.
+	elapsed time.Duration
+
+	opacity      float64 // Global scene opacity (0.0 to 1.0)
+	palette      *Palette
+	scale        float64 // Current sprite scale, lowered by AdaptiveScale
+	landGradient float64 // Vertical lightness delta across land tiles (0 = flat)
+	flow         bool    // Animate dots along edges by Edge.Flow
+	antialias    bool    // Smooth circle and line edges
+	maxProcesses int     // Processes drawn per frame (0 = unlimited)
+	attachments  bool    // Draw lines from processes to their lands
+	pixelSnap    bool    // Round GPU positions to whole pixels
+	legend       bool    // Draw a key of the types in the state
+	shadows      bool    // Draw shadows under processes
+	shadowAlpha  float64 // Opacity of process shadows
+	debugGrid    bool    // Draw grid lines and an origin marker
+
+	// Background image fitted to the frame, nil for a flat fill
+	background *image.RGBA
+
+	highlights map[string]bool  // Land and process IDs set by Highlight
+	filters    []Filter         // Post-processing run on finished frames
+	quantizer  *outputQuantizer // Set by SetOutputPalette, run after filters
+
+	// Frame timing, updated by the Frames loop
+	renderAvg  time.Duration // Moving average of render time per frame
+	slowFrames int           // Consecutive frames below 80% of FrameRate
+	instantFPS float64       // Rate implied by the last gap between frames
+	smoothFPS  float64       // Moving average of instantFPS
+	drift      time.Duration // How far the Frames loop lags its schedule
+
+	// For GPU mode: ebiten game running in background, started on first use
 	game      *ebitenGame
-	gameReady chan struct{}
+	gameOnce  sync.Once
+	gameReady chan struct{} // Closed by the game's first Update
+	gameDone  chan struct{} // Closed once ebiten.RunGame returns
 	frameCh   chan image.Image
+
+	// CaptureRegion requests waiting for the game's next frame
+	regionCh chan regionRequest
+
+	// Set when Options.Interpolate is enabled
+	interp *interpolator
+
+	// Set by SetSpawnAnimation
+	spawn *spawnTracker
+	// Recent positions of processes, set by SetTrailsEnabled
+	trails *trailTracker
+
+	// Callbacks registered with OnFrame
+	frameCallbacks []func(FrameStats)
+
+	// Optional caller-provided buffer reused by captureFrame
+	captureBuf *image.RGBA
+
+	// Sprites drawn by the last RenderDelta, diffed by the next one
+	deltaScene *deltaScene
+
+	lastFrame image.Image // Most recent frame from Render or the Frames loop
+
+	dropped  atomic.Int64 // Frames discarded by backpressure
+	produced atomic.Int64 // Frames emitted by the Frames loop
 }
 
 // New creates a new renderer with the given options
@@ -60,28 +227,129 @@ func New(opts Options) (*Renderer, error) {
 	if opts.Scale == 0 {
 		opts.Scale = 1.0
 	}
+	if opts.Palette == nil {
+		opts.Palette = DefaultPalette()
+	}
+	if opts.FrameBuffer == 0 {
+		opts.FrameBuffer = 2
+	}
+	if opts.Manual {
+		opts.UseGPU = false
+		opts.RenderWorkers = 1
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = realClock{}
+	}
 
 	r := &Renderer{
-		opts:      opts,
-		gameReady: make(chan struct{}),
-		frameCh:   make(chan image.Image, 2),
+		opts:        opts,
+		opacity:     1.0,
+		antialias:   true,
+		shadowAlpha: defaultShadowAlpha,
+		palette:     opts.Palette,
+		scale:       opts.Scale,
+		done:        make(chan struct{}),
+		gameReady:   make(chan struct{}),
+		regionCh:    make(chan regionRequest),
+		frameCh:     make(chan image.Image, opts.FrameBuffer),
 	}
 
-	if opts.UseGPU {
-		r.startEbitenGame()
+	if opts.Interpolate {
+		r.interp = newInterpolator(time.Second / time.Duration(opts.FrameRate))
+	}
+
+	if opts.SnapshotSignal != nil {
+		r.watchSnapshotSignal()
 	}
 
 	return r, nil
 }
 
-// startEbitenGame starts ebiten in a background goroutine
+// validate rejects options that would fail or exhaust memory further down,
+// naming the offending field. Zero values have been defaulted by then.
+func (o Options) validate() error {
+	switch {
+	case o.Width < 1 || o.Width > 16384:
+		return fmt.Errorf("%w: Width %d is outside [1, 16384]", ErrInvalidOptions, o.Width)
+	case o.Height < 1 || o.Height > 16384:
+		return fmt.Errorf("%w: Height %d is outside [1, 16384]", ErrInvalidOptions, o.Height)
+	case o.FrameRate < 1 || o.FrameRate > 240:
+		return fmt.Errorf("%w: FrameRate %d is outside [1, 240]", ErrInvalidOptions, o.FrameRate)
+	case !(o.Scale > 0 && o.Scale <= 100):
+		return fmt.Errorf("%w: Scale %v is outside (0, 100]", ErrInvalidOptions, o.Scale)
+	case o.FrameBuffer < 0:
+		return fmt.Errorf("%w: FrameBuffer %d is negative", ErrInvalidOptions, o.FrameBuffer)
+	case o.RenderWorkers < 0:
+		return fmt.Errorf("%w: RenderWorkers %d is negative", ErrInvalidOptions, o.RenderWorkers)
+	case o.Manual && o.SnapshotSignal != nil:
+		return fmt.Errorf("%w: SnapshotSignal needs a goroutine, which Manual rules out", ErrInvalidOptions)
+	}
+	return nil
+}
+
+// NewHeadless creates a software-only renderer. It never starts ebiten, so
+// it is safe to use in tests, CI, and environments without a display.
+func NewHeadless(opts Options) (*Renderer, error) {
+	opts.UseGPU = false
+	return New(opts)
+}
+
+// gameStartTimeout is how long Render waits for the ebiten game to start
+// before falling back to software
+const gameStartTimeout = 100 * time.Millisecond
+
+// waitForGame starts the ebiten game if it isn't running yet and waits
+// until it has run its first Update. It returns false if the game exits,
+// the renderer is closed, or timeout passes before then. The game keeps
+// starting in the background after a timeout.
+func (r *Renderer) waitForGame(timeout time.Duration) bool {
+	r.gameOnce.Do(r.startEbitenGame)
+	select {
+	case <-r.gameReady:
+		return true
+	case <-r.gameDone:
+		return false
+	case <-r.done:
+		return false
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// startEbitenGame starts ebiten in a background goroutine. It must only be
+// called through gameOnce.
 func (r *Renderer) startEbitenGame() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+
 	r.game = &ebitenGame{
 		renderer:  r,
 		offscreen: ebiten.NewImage(r.opts.Width, r.opts.Height),
 	}
+	white := ebiten.NewImage(3, 3)
+	white.Fill(color.White)
+	// Sample from the center pixel so filtering never reaches the edges
+	r.game.white = white.SubImage(image.Rect(1, 1, 2, 2)).(*ebiten.Image)
+	if r.opts.DoubleBuffer {
+		bounds := image.Rect(0, 0, r.opts.Width, r.opts.Height)
+		r.game.buffers = [2]*image.RGBA{image.NewRGBA(bounds), image.NewRGBA(bounds)}
+		r.game.free = make(chan *image.RGBA, len(r.game.buffers))
+		for _, buf := range r.game.buffers {
+			r.game.free <- buf
+		}
+	}
+	r.gameDone = make(chan struct{})
 
 	go func() {
+		defer close(r.gameDone)
+
 		// Set window size (even for headless, this sets the logical size)
 		ebiten.SetWindowSize(r.opts.Width, r.opts.Height)
 		ebiten.SetWindowTitle("nimsforestsprites")
@@ -89,10 +357,6 @@ func (r *Renderer) startEbitenGame() {
 		// Run game in background - this blocks until game exits
 		ebiten.RunGame(r.game)
 	}()
-
-	// Wait a moment for ebiten to initialize
-	time.Sleep(100 * time.Millisecond)
-	close(r.gameReady)
 }
 
 // ebitenGame implements ebiten.Game interface
@@ -100,10 +364,36 @@ type ebitenGame struct {
 	renderer  *Renderer
 	offscreen *ebiten.Image
 	ready     bool
+
+	// Capture buffers used with Options.DoubleBuffer, and those of them
+	// not queued on frameCh or being copied
+	buffers [2]*image.RGBA
+	free    chan *image.RGBA
+
+	// GPU copy of the background image set with SetBackgroundImage
+	bgSource *image.RGBA
+	bgImage  *ebiten.Image
+
+	// Land tiles are batched into one DrawTriangles call per frame
+	white    *ebiten.Image // Solid source texture for batched fills
+	vertices []ebiten.Vertex
+	indices  []uint16
+
+	antialias bool // Set from the style of the frame being drawn
 }
 
 func (g *ebitenGame) Update() error {
-	g.ready = true
+	g.renderer.mu.RLock()
+	closed := g.renderer.closed
+	g.renderer.mu.RUnlock()
+
+	if closed {
+		return ebiten.Termination
+	}
+	if !g.ready {
+		g.ready = true
+		close(g.renderer.gameReady)
+	}
 	return nil
 }
 
@@ -113,23 +403,28 @@ func (g *ebitenGame) Draw(screen *ebiten.Image) {
 	}
 
 	g.renderer.mu.RLock()
-	state := g.renderer.state
-	tick := g.renderer.tick
+	if g.renderer.closed {
+		g.renderer.mu.RUnlock()
+		return
+	}
+	state := g.renderer.frameStateLocked()
+	phase := animationPhase(g.renderer.elapsed)
+	style := g.renderer.styleLocked()
 	g.renderer.mu.RUnlock()
 
 	// Clear
 	g.offscreen.Clear()
 
 	// Draw scene
-	g.drawScene(g.offscreen, state, tick)
+	g.drawScene(g.offscreen, state, phase, style)
 
 	// Copy to screen
 	screen.DrawImage(g.offscreen, nil)
+	g.serveRegions(style)
 
 	// Capture frame for output
-	select {
-	case g.renderer.frameCh <- g.captureFrame():
-	default:
+	if img := g.captureFrame(); img != nil {
+		g.sendCapture(img)
 	}
 }
 
@@ -137,271 +432,1421 @@ func (g *ebitenGame) Layout(outsideWidth, outsideHeight int) (int, int) {
 	return g.renderer.opts.Width, g.renderer.opts.Height
 }
 
-func (g *ebitenGame) captureFrame() image.Image {
-	bounds := image.Rect(0, 0, g.renderer.opts.Width, g.renderer.opts.Height)
-	img := image.NewRGBA(bounds)
+// captureFrame reads the drawn frame back from the GPU. It returns nil
+// when both double buffers are still in use.
+func (g *ebitenGame) captureFrame() *image.RGBA {
+	g.renderer.mu.RLock()
+	img := g.renderer.captureBuf
+	g.renderer.mu.RUnlock()
+
+	switch {
+	case img != nil:
+	case g.free != nil:
+		if img = g.nextBuffer(); img == nil {
+			return nil
+		}
+	default:
+		bounds := image.Rect(0, 0, g.renderer.opts.Width, g.renderer.opts.Height)
+		img = image.NewRGBA(bounds)
+	}
 	g.offscreen.ReadPixels(img.Pix)
+
+	g.renderer.mu.RLock()
+	style := g.renderer.styleLocked()
+	g.renderer.mu.RUnlock()
+	finishFrame(img, style)
 	return img
 }
 
-func (g *ebitenGame) drawScene(screen *ebiten.Image, state State, tick int) {
-	// Draw dark background
-	screen.Fill(color.RGBA{20, 25, 30, 255})
+// nextBuffer returns a free capture buffer. With DropOldest it recycles
+// the older queued frame when both are waiting; otherwise it returns nil
+// until the consumer takes one.
+func (g *ebitenGame) nextBuffer() *image.RGBA {
+	select {
+	case buf := <-g.free:
+		return buf
+	default:
+	}
+
+	r := g.renderer
+	if r.opts.Backpressure == DropOldest {
+		select {
+		case old := <-r.frameCh:
+			r.dropped.Add(1)
+			if buf, ok := old.(*image.RGBA); ok && g.isBuffer(buf) {
+				return buf
+			}
+		default:
+		}
+	}
+	return nil
+}
+
+// sendCapture queues a captured frame on frameCh under the backpressure
+// policy. A double buffer whose frame is discarded goes back into use.
+func (g *ebitenGame) sendCapture(img *image.RGBA) {
+	r := g.renderer
+	policy := r.opts.Backpressure
+	if g.free != nil && policy == DropOldest {
+		// Make room here so the discarded frame's buffer is not lost
+		if len(r.frameCh) == cap(r.frameCh) {
+			select {
+			case old := <-r.frameCh:
+				g.release(old)
+				r.dropped.Add(1)
+			default:
+			}
+		}
+		policy = DropNewest
+	}
+
+	n := sendFrame(r.frameCh, image.Image(img), policy, r.done, nil)
+	r.dropped.Add(int64(n))
+	if n > 0 {
+		g.release(img)
+	}
+}
+
+// keep returns a frame received from frameCh that the caller may hold on
+// to. A double-buffered frame is copied and its buffer released.
+func (g *ebitenGame) keep(img image.Image) image.Image {
+	buf, ok := img.(*image.RGBA)
+	if !ok || !g.isBuffer(buf) {
+		return img
+	}
+	out := image.NewRGBA(buf.Rect)
+	copy(out.Pix, buf.Pix)
+	g.release(buf)
+	return out
+}
+
+// release returns a double buffer to the free list. Other images, such
+// as one set with SetCaptureBuffer, are ignored.
+func (g *ebitenGame) release(img image.Image) {
+	buf, ok := img.(*image.RGBA)
+	if !ok || !g.isBuffer(buf) {
+		return
+	}
+	select {
+	case g.free <- buf:
+	default:
+	}
+}
+
+func (g *ebitenGame) isBuffer(buf *image.RGBA) bool {
+	return buf != nil && (buf == g.buffers[0] || buf == g.buffers[1])
+}
+
+// drainCaptures discards every frame queued on frameCh, releasing their
+// buffers, and returns how many there were
+func (g *ebitenGame) drainCaptures() int {
+	for n := 0; ; n++ {
+		select {
+		case img := <-g.renderer.frameCh:
+			g.release(img)
+		default:
+			return n
+		}
+	}
+}
+
+func (g *ebitenGame) drawScene(screen *ebiten.Image, state State, phase float64, style drawStyle) {
+	opacity, palette := float32(style.opacity), style.palette
+	scale, gradient := style.scale, style.landGradient
+	g.antialias = style.antialias
+
+	// Draw background
+	screen.Fill(palette.Background)
+	if style.background != nil {
+		screen.DrawImage(g.backgroundImage(style.background), nil)
+	}
 
 	if state == nil {
 		return
 	}
 
 	// Draw lands as grid
-	lands := state.Lands()
-	tileSize := int(64 * g.renderer.opts.Scale)
+	lands := sortLands(state.Lands())
+	tileSize := int(64 * scale)
 	startX := 100
 	startY := 100
 
+	// Draw edges beneath the land tiles
+	half := float32(tileSize-2) / 2
+	snap := style.pixelSnap
+	lifts := landLifts(lands, tileSize)
+	segments := resolveEdges(state, lands)
+	for _, seg := range segments {
+		x0 := snapPixel(float32(startX+int(seg.from.X*float64(tileSize)))+half, snap)
+		y0 := snapPixel(float32(startY+int(seg.from.Y*float64(tileSize))-lifts[seg.from.ID])+half, snap)
+		x1 := snapPixel(float32(startX+int(seg.to.X*float64(tileSize)))+half, snap)
+		y1 := snapPixel(float32(startY+int(seg.to.Y*float64(tileSize))-lifts[seg.to.ID])+half, snap)
+		c := fadeColor(edgeColor, seg.fade()*float64(opacity))
+		vector.StrokeLine(screen, x0, y0, x1, y1, 2, c, style.antialias)
+	}
+
+	width, height := g.renderer.opts.Width, g.renderer.opts.Height
+	pulse := math.Mod(phase, 60) / 60.0
+	if pulse > 0.5 {
+		pulse = 1.0 - pulse
+	}
 	for _, land := range lands {
+		lift := lifts[land.ID]
 		x := float32(startX + int(land.X*float64(tileSize)))
-		y := float32(startY + int(land.Y*float64(tileSize)))
+		y := float32(startY + int(land.Y*float64(tileSize)) - lift)
+		if !onScreen(int(x), int(y), tileSize, tileSize+lift, width, height) {
+			continue
+		}
 
 		// Get land color with pulse animation
-		landColor := getLandColor(land.Type)
-		pulse := float64(tick%60) / 60.0
-		if pulse > 0.5 {
-			pulse = 1.0 - pulse
-		}
+		landColor := palette.LandColor(land.Type)
 		landColor.A = uint8(200 + pulse*55)
 
-		// Draw land tile using vector
-		drawFilledRect(screen, x, y, float32(tileSize-2), float32(tileSize-2), landColor)
+		top, bottom := shadeColor(landColor, gradient), shadeColor(landColor, -gradient)
+		g.appendRect(screen, x, y, float32(tileSize-2), float32(tileSize-2), top, bottom, opacity)
+		if lift > 0 {
+			side := landSide(int(x), int(y), tileSize-2, lift)
+			c := shadeColor(landColor, elevationShade)
+			g.appendRect(screen, float32(side.Min.X), float32(side.Min.Y), float32(side.Dx()), float32(side.Dy()), c, c, opacity)
+		}
+	}
+	g.flushTriangles(screen)
+
+	// Highlighted lands glow on top of their neighbors
+	if len(style.highlights) > 0 {
+		glow := opacity * float32(highlightAlpha(phase))
+		for _, land := range lands {
+			if !style.highlights[land.ID] {
+				continue
+			}
+			x := startX + int(land.X*float64(tileSize))
+			y := startY + int(land.Y*float64(tileSize)) - lifts[land.ID]
+			for _, strip := range highlightFrame(x, y, tileSize-2) {
+				g.appendRect(screen, float32(strip.Min.X), float32(strip.Min.Y), float32(strip.Dx()), float32(strip.Dy()), highlightColor, highlightColor, glow)
+			}
+		}
+		g.flushTriangles(screen)
 	}
 
-	// Draw processes
-	processes := state.Processes()
-	for _, proc := range processes {
-		px := float32(startX+int(proc.X)*tileSize) + float32(tileSize/2)
-		py := float32(startY+int(proc.Y)*tileSize) + float32(tileSize/2)
+	if style.debugGrid {
+		lines, origin := gridRects(tileSize, width, height)
+		for _, line := range lines {
+			g.appendRect(screen, float32(line.Min.X), float32(line.Min.Y), float32(line.Dx()), float32(line.Dy()), gridColor, gridColor, opacity)
+		}
+		for _, bar := range origin {
+			g.appendRect(screen, float32(bar.Min.X), float32(bar.Min.Y), float32(bar.Dx()), float32(bar.Dy()), gridOriginColor, gridOriginColor, opacity)
+		}
+		g.flushTriangles(screen)
+	}
+
+	processes, hidden := cullProcesses(sortProcesses(state.Processes()), style.maxProcesses)
+	offsets := fanOut(processes, tileSize)
+
+	// Shadows belong to the ground, beneath every sprite
+	if style.shadows {
+		for i, proc := range processes {
+			x := startX + int(proc.X*float64(tileSize)) + offsets[i].X + tileSize/2
+			y := startY + int(proc.Y*float64(tileSize)) + offsets[i].Y + tileSize/2 - lifts[proc.LandID]
+			cx, cy, rx, ry := shadowEllipse(x, y, scaledRadius(8, processScale(state, proc.ID)))
+			if !onScreen(cx-rx, cy-ry, 2*rx+1, 2*ry+1, width, height) {
+				continue
+			}
+			alpha := opacity * float32(style.shadowAlpha*processAlpha(state, proc.ID))
+			drawFilledEllipse(screen, snapPixel(float32(cx), snap), snapPixel(float32(cy), snap), rx, ry, shadowColor, alpha, style.antialias)
+		}
+	}
+
+	// Trails fade toward the oldest position, beneath every sprite
+	for _, seg := range trailSegments(state, tileSize, lifts) {
+		c := fadeColor(palette.ProcessColor(seg.typ), seg.alpha*float64(opacity))
+		x0, y0 := snapPixel(float32(seg.x0), snap), snapPixel(float32(seg.y0), snap)
+		x1, y1 := snapPixel(float32(seg.x1), snap), snapPixel(float32(seg.y1), snap)
+		vector.StrokeLine(screen, x0, y0, x1, y1, trailWidth, c, style.antialias)
+	}
+
+	// Flow dots travel on top of the tiles so they stay visible
+	if style.flow {
+		for _, seg := range segments {
+			for _, t := range seg.flowDots(phase) {
+				x := snapPixel(float32(startX)+float32(lerp(seg.from.X, seg.to.X, t)*float64(tileSize))+half, snap)
+				lift := lerp(float64(lifts[seg.from.ID]), float64(lifts[seg.to.ID]), t)
+				y := snapPixel(float32(startY)+float32(lerp(seg.from.Y, seg.to.Y, t)*float64(tileSize)-lift)+half, snap)
+				drawFilledCircle(screen, x, y, 3, flowDotColor, opacity*float32(seg.fade()), style.antialias)
+			}
+		}
+	}
+
+	// Attachment lines sit beneath every process sprite
+	if style.attachments {
+		byID := landsByID(lands)
+		for i, proc := range processes {
+			land, ok := byID[proc.LandID]
+			if !ok {
+				continue
+			}
+			lx := snapPixel(float32(startX+int(land.X*float64(tileSize)))+half, snap)
+			ly := snapPixel(float32(startY+int(land.Y*float64(tileSize))-lifts[land.ID])+half, snap)
+			px := float32(startX+int(proc.X*float64(tileSize))+offsets[i].X) + float32(tileSize/2)
+			py := float32(startY+int(proc.Y*float64(tileSize))+offsets[i].Y-lifts[proc.LandID]) + float32(tileSize/2)
+			py = snapPixel(py+float32(processBounce(phase, proc.X)), snap)
+			c := fadeColor(attachmentColor, float64(opacity)*processAlpha(state, proc.ID))
+			vector.StrokeLine(screen, lx, ly, px, py, 1, c, style.antialias)
+		}
+	}
+
+	for i, proc := range processes {
+		px := float32(startX+int(proc.X*float64(tileSize))+offsets[i].X) + float32(tileSize/2)
+		py := float32(startY+int(proc.Y*float64(tileSize))+offsets[i].Y-lifts[proc.LandID]) + float32(tileSize/2)
 
 		// Bounce animation
-		bounce := sin(float64(tick)/10.0+proc.X*0.5) * 3
-		py += float32(bounce)
+		py = snapPixel(py+float32(processBounce(phase, proc.X)), snap)
+		if !onScreen(int(px)-10, int(py)-10, 21, 21, width, height) {
+			continue
+		}
 
-		procColor := getProcessColor(proc.Type)
-		drawFilledCircle(screen, px, py, 8, procColor)
+		procColor := palette.ProcessColor(proc.Type)
+		alpha := opacity * float32(processAlpha(state, proc.ID))
+		size := processScale(state, proc.ID)
+		if style.highlights[proc.ID] {
+			drawFilledCircle(screen, px, py, float32(scaledRadius(13, size)), highlightColor, alpha*float32(highlightAlpha(phase)), style.antialias)
+		}
+		if outline, a, ok := palette.statusOutline(proc.Status, phase); ok {
+			drawFilledCircle(screen, px, py, float32(scaledRadius(10, size)), outline, alpha*float32(a), style.antialias)
+		}
+		drawFilledCircle(screen, px, py, float32(scaledRadius(8, size)), procColor, alpha, style.antialias)
+	}
+	if hidden > 0 {
+		for _, rect := range overflowRects(hidden, width, height) {
+			g.appendRect(screen, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), overflowColor, overflowColor, opacity)
+		}
+		g.flushTriangles(screen)
+	}
+	if style.legend {
+		for _, item := range legendLayout(state, palette, width, height) {
+			g.appendRect(screen, float32(item.rect.Min.X), float32(item.rect.Min.Y), float32(item.rect.Dx()), float32(item.rect.Dy()), item.c, item.c, opacity)
+		}
+		g.flushTriangles(screen)
 	}
 
 	// Frame indicator
-	frameX := float32(10 + (tick%60)*2)
-	drawFilledRect(screen, frameX, 10, 4, 4, color.RGBA{100, 200, 100, 200})
+	frameX := float32(10 + int(math.Mod(phase, 60))*2)
+	drawFilledRect(screen, frameX, 10, 4, 4, color.RGBA{100, 200, 100, 200}, opacity)
+}
+
+// drawStyle holds the renderer settings a frame is drawn with. It is read
+// in one go under mu so a frame never mixes old and new settings.
+type drawStyle struct {
+	opacity      float64
+	palette      *Palette
+	scale        float64
+	landGradient float64
+	flow         bool
+	antialias    bool
+	maxProcesses int
+	attachments  bool
+	pixelSnap    bool
+	legend       bool
+	shadows      bool
+	shadowAlpha  float64
+	debugGrid    bool
+	background   *image.RGBA
+	highlights   map[string]bool
+	filters      []Filter
+	quantizer    *outputQuantizer
+}
+
+func (r *Renderer) styleLocked() drawStyle {
+	return drawStyle{
+		opacity:      r.opacity,
+		palette:      r.palette,
+		scale:        r.scale,
+		landGradient: r.landGradient,
+		flow:         r.flow,
+		antialias:    r.antialias,
+		maxProcesses: r.maxProcesses,
+		attachments:  r.attachments,
+		pixelSnap:    r.pixelSnap,
+		legend:       r.legend,
+		shadows:      r.shadows,
+		shadowAlpha:  r.shadowAlpha,
+		debugGrid:    r.debugGrid,
+		background:   r.background,
+		highlights:   r.highlights,
+		filters:      r.filters,
+		quantizer:    r.quantizer,
+	}
+}
+
+// SetGlobalOpacity scales the alpha of everything drawn on top of the
+// background. At 0 only the background is visible; at 1 the scene renders
+// normally. Values are clamped to [0, 1].
+func (r *Renderer) SetGlobalOpacity(a float64) {
+	if a < 0 {
+		a = 0
+	}
+	if a > 1 {
+		a = 1
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.opacity = a
+}
+
+// SetLandGradient shades land tiles lighter toward the top and darker
+// toward the bottom to suggest height. strength is the lightness change at
+// each edge; 0 draws flat tiles. Values are clamped to [0, 1].
+func (r *Renderer) SetLandGradient(strength float64) {
+	strength = max(0, min(1, strength))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.landGradient = strength
+}
+
+// SetFlowEnabled turns on dots traveling along each edge from its source
+// land to its destination, with speed and density following Edge.Flow
+func (r *Renderer) SetFlowEnabled(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flow = enabled
+}
+
+// SetAntialias turns smoothing of circle and line edges on or off (on by
+// default). With it off, every pixel is either fully covered or untouched,
+// which suits pixel-art output and draws faster on the GPU.
+func (r *Renderer) SetAntialias(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.antialias = enabled
+}
+
+// SetPalette replaces the colors used for the background and for land and
+// process types. A nil palette restores DefaultPalette.
+func (r *Renderer) SetPalette(p *Palette) {
+	if p == nil {
+		p = DefaultPalette()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.palette = p
 }
 
 // Update updates the state for the next frame
 func (r *Renderer) Update(state State) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	r.setStateLocked(state)
+}
+
+// setStateLocked stores the state to render. r.mu must be held for writing.
+func (r *Renderer) setStateLocked(state State) {
 	r.state = state
+	if r.interp != nil {
+		r.interp.update(state, r.interpolationTimeLocked())
+	}
+	if r.spawn != nil {
+		r.spawn.update(state, r.interpolationTimeLocked())
+	}
+	if r.trails != nil {
+		r.trails.update(state, r.interpolationTimeLocked())
+	}
+}
+
+// frameStateLocked returns the state to draw for a frame produced now,
+// interpolated, with spawn animations applied and trails attached when
+// enabled. r.mu must
+// be held.
+func (r *Renderer) frameStateLocked() State {
+	state := r.state
+	if state == nil {
+		return nil
+	}
+	if r.interp != nil {
+		state = r.interp.at(r.interpolationTimeLocked())
+	}
+	if r.spawn != nil {
+		state = r.spawn.at(state, r.interpolationTimeLocked())
+	}
+	if r.trails != nil {
+		state = r.trails.at(state, r.interpolationTimeLocked())
+	}
+	return state
+}
+
+// interpolationTimeLocked returns the time interpolation treats as now.
+// With Options.Manual it is derived from the tick, so a sequence of Step
+// and Update calls interpolates the same way on every run. r.mu must be
+// held.
+func (r *Renderer) interpolationTimeLocked() time.Time {
+	if r.opts.Manual {
+		return time.Unix(0, 0).Add(time.Duration(r.tick) * r.frameDuration())
+	}
+	return r.opts.Clock.Now()
+}
+
+// SetCaptureBuffer sets a buffer that GPU frame capture reuses instead of
+// allocating a new image per frame. Every captured frame aliases buf, so
+// this is only safe with a single consumer that is done with a frame before
+// requesting the next. Pass nil to go back to allocating per frame.
+func (r *Renderer) SetCaptureBuffer(buf *image.RGBA) error {
+	if buf != nil {
+		want := image.Rect(0, 0, r.opts.Width, r.opts.Height)
+		if buf.Bounds() != want || buf.Stride != 4*r.opts.Width {
+			return fmt.Errorf("capture buffer bounds %v do not match frame size %dx%d", buf.Bounds(), r.opts.Width, r.opts.Height)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.captureBuf = buf
+	return nil
 }
 
-// Render renders a single frame with the current state
+// Render renders a single frame with the current state. A nil state
+// renders only the background. With UseGPU, the first call starts ebiten
+// and waits up to 100ms for it, rendering in software if it is not ready
+// by then.
 func (r *Renderer) Render(state State) image.Image {
+	frame, _ := r.render(state)
+	return frame
+}
+
+// render implements Render. It also reports whether the frame came from
+// the GPU rather than the software fallback.
+func (r *Renderer) render(state State) (image.Image, bool) {
 	r.mu.Lock()
-	r.state = state
+	r.setStateLocked(state)
 	r.tick++
+	r.advanceLocked(r.frameDuration())
+	tick := r.tick
+	phase := animationPhase(r.elapsed)
+	state = r.frameStateLocked()
+	closed := r.closed
 	r.mu.Unlock()
 
-	if r.closed {
-		return nil
+	if closed {
+		return nil, false
 	}
 
-	if r.opts.UseGPU {
-		// Wait for ebiten to be ready
-		<-r.gameReady
+	start := r.opts.Clock.Now()
+	var frame image.Image
+	gpu := false
+	if r.opts.UseGPU && r.waitForGame(gameStartTimeout) {
+		// Queued frames were captured before this state was set; showing
+		// one would lag behind, or keep a cleared scene on screen
+		r.dropped.Add(int64(r.game.drainCaptures()))
 
 		// Wait for next frame from ebiten
 		select {
-		case frame := <-r.frameCh:
-			return frame
+		case frame = <-r.frameCh:
+			frame = r.game.keep(frame)
+			gpu = true
 		case <-time.After(100 * time.Millisecond):
 			// Timeout - return software rendered frame
-			return r.renderFrameSoftware(state)
+			frame = r.renderFrameSoftwareAt(state, phase)
 		}
+	} else {
+		frame = r.renderFrameSoftwareAt(state, phase)
 	}
+	r.emitFrameStats(state, tick, r.opts.Clock.Now().Sub(start), false)
+
+	r.mu.Lock()
+	r.lastFrame = frame
+	r.mu.Unlock()
+
+	return frame, gpu
+}
+
+// Pause freezes animation in the Frames stream. Frames keep being emitted at
+// the configured rate, but the tick no longer advances, so they repeat the
+// current animation phase. Pausing before Frames is started takes effect
+// once it starts.
+func (r *Renderer) Pause() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paused = true
+}
 
-	return r.renderFrameSoftware(state)
+// Resume continues animation in the Frames stream from where it was paused
+func (r *Renderer) Resume() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paused = false
+}
+
+// Frame is a rendered frame along with when it was produced
+type Frame struct {
+	Image image.Image
+	Tick  int       // Renderer tick (frame count) the frame was rendered at
+	Time  time.Time // Wall-clock time the frame was due (see Options.CatchUp)
+	// DroppedSince is how many frames were discarded because the channel
+	// was full since the previous delivered frame
+	DroppedSince int
 }
 
 // Frames returns a channel that receives continuous frames
 func (r *Renderer) Frames(ctx context.Context) <-chan image.Image {
-	frames := make(chan image.Image, 2)
+	frames := make(chan image.Image, r.opts.FrameBuffer)
+	if r.opts.Manual {
+		close(frames)
+		return frames
+	}
 
 	go func() {
 		defer close(frames)
+		r.frameLoop(ctx, func(f Frame) int {
+			return sendFrame(frames, f.Image, r.opts.Backpressure, r.done, ctx.Done())
+		})
+	}()
 
-		frameDuration := time.Second / time.Duration(r.opts.FrameRate)
-		ticker := time.NewTicker(frameDuration)
-		defer ticker.Stop()
+	return frames
+}
+
+// FramesWithInfo is like Frames but delivers each image with its tick,
+// timestamp, and how many frames were dropped before it, so a consumer can
+// tell when it is falling behind.
+func (r *Renderer) FramesWithInfo(ctx context.Context) <-chan Frame {
+	frames := make(chan Frame, r.opts.FrameBuffer)
+	if r.opts.Manual {
+		close(frames)
+		return frames
+	}
+
+	go func() {
+		defer close(frames)
+		r.frameLoop(ctx, func(f Frame) int {
+			return sendFrame(frames, f, r.opts.Backpressure, r.done, ctx.Done())
+		})
+	}()
+
+	return frames
+}
+
+// FramesResult is like Frames, with a second channel that reports why the
+// stream ended: it receives ErrRendererClosed if the renderer was closed,
+// and is closed without a value when ctx was cancelled. If both happen
+// together, the close wins. The error channel is buffered, so it can be
+// read after the frames channel closes.
+func (r *Renderer) FramesResult(ctx context.Context) (<-chan image.Image, <-chan error) {
+	frames := make(chan image.Image, r.opts.FrameBuffer)
+	errs := make(chan error, 1)
+	if r.opts.Manual {
+		close(frames)
+		close(errs)
+		return frames, errs
+	}
+
+	go func() {
+		err := r.frameLoop(ctx, func(f Frame) int {
+			return sendFrame(frames, f.Image, r.opts.Backpressure, r.done, ctx.Done())
+		})
+		close(frames)
+		if err != nil {
+			errs <- err
+		}
+		close(errs)
+	}()
+
+	return frames, errs
+}
+
+// frameLoop produces frames at the configured rate and hands them to emit,
+// which returns how many frames it had to discard. It returns nil when ctx
+// is done, or ErrRendererClosed once the renderer is closed.
+func (r *Renderer) frameLoop(ctx context.Context, emit func(Frame) int) error {
+	if r.opts.UseGPU {
+		// Frames are rendered in software until the game is ready
+		r.gameOnce.Do(r.startEbitenGame)
+	}
+
+	interval := r.frameDuration()
+	ticker := r.opts.Clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	var fetcher *stateFetcher
+	if r.opts.StateProvider != nil {
+		fetcher = &stateFetcher{provider: r.opts.StateProvider}
+	}
+
+	begin := r.opts.Clock.Now()
+	last := begin
+	slots := 0 // Frame intervals handled so far, emitted or skipped
+	var lastEmit time.Time
+	droppedSince := 0
+	var lastKey uint64
+	emitted := false
+	for {
+		select {
+		case <-ctx.Done():
+			return r.closedErr()
+		case <-r.done:
+			return ErrRendererClosed
+		case now := <-ticker.C():
+			// The ticker coalesces ticks the loop was too slow to take, so
+			// count intervals against the start time instead
+			due := int(now.Sub(begin) / interval)
+			frames := 1
+			if behind := due - slots - 1; behind > 0 && r.opts.CatchUp {
+				frames += min(behind, maxCatchUp)
+			}
+
+			var pulled State
+			fetched := false
+			if fetcher != nil {
+				pulled, fetched = fetcher.fetch(r.opts.Clock, interval/2)
+			}
+
+			// Catch-up frames are stamped with the times they were due
+			for i := frames - 1; i >= 0; i-- {
+				at := now.Add(-time.Duration(i) * interval)
 
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
 				r.mu.Lock()
 				if r.closed {
 					r.mu.Unlock()
-					return
+					return ErrRendererClosed
 				}
-				r.tick++
-				state := r.state
+				if fetched {
+					r.setStateLocked(pulled)
+					fetched = false
+				}
+				if !r.paused {
+					r.tick++
+					r.advanceLocked(at.Sub(last))
+				}
+				last = at
+				tick := r.tick
+				phase := animationPhase(r.elapsed)
+				state := r.frameStateLocked()
 				r.mu.Unlock()
 
+				if r.opts.SkipUnchanged {
+					key := r.drawInputsHash(state, phase)
+					if emitted && key == lastKey {
+						continue
+					}
+					lastKey, emitted = key, true
+				}
+
+				start := r.opts.Clock.Now()
 				var frame image.Image
 				if r.opts.UseGPU {
 					select {
 					case frame = <-r.frameCh:
+						frame = r.game.keep(frame)
 					default:
-						frame = r.renderFrameSoftware(state)
+						frame = r.renderFrameSoftwareAt(state, phase)
 					}
 				} else {
-					frame = r.renderFrameSoftware(state)
+					frame = r.renderFrameSoftwareAt(state, phase)
 				}
+				elapsed := r.opts.Clock.Now().Sub(start)
+				r.recordRenderTime(elapsed)
 
-				select {
-				case frames <- frame:
-				default:
+				// FPS follows when frames actually go out, not when they were due
+				emitAt := r.opts.Clock.Now()
+				r.mu.Lock()
+				r.lastFrame = frame
+				if !lastEmit.IsZero() {
+					r.recordFrameIntervalLocked(emitAt.Sub(lastEmit))
+				}
+				r.mu.Unlock()
+				lastEmit = emitAt
+
+				n := emit(Frame{Image: frame, Tick: tick, Time: at, DroppedSince: droppedSince})
+				r.dropped.Add(int64(n))
+				r.produced.Add(1)
+
+				// DropOldest always delivers the new frame; what it discarded
+				// was queued ahead of it and counts toward the next one
+				delivered := n == 0 || r.opts.Backpressure == DropOldest
+				r.emitFrameStats(state, tick, elapsed, !delivered)
+				if delivered {
+					droppedSince = n
+				} else {
+					droppedSince += n
 				}
 			}
+
+			slots += frames
+			r.mu.Lock()
+			r.drift = time.Duration(max(0, due-slots)) * interval
+			r.mu.Unlock()
 		}
-	}()
+	}
+}
 
-	return frames
+// closedErr returns ErrRendererClosed if the renderer is closed, else nil
+func (r *Renderer) closedErr() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return ErrRendererClosed
+	}
+	return nil
+}
+
+// drawInputsHash returns an FNV-1a hash of everything a frame is drawn
+// from: the state, the animation phase, and the renderer's style settings.
+// Equal hashes mean the frames would be identical.
+func (r *Renderer) drawInputsHash(state State, phase float64) uint64 {
+	r.mu.RLock()
+	style := r.styleLocked()
+	r.mu.RUnlock()
+
+	h := fnv.New64a()
+	var buf [8]byte
+	writeFloat := func(f float64) {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+		h.Write(buf[:])
+	}
+	writeString := func(s string) {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+
+	fmt.Fprintf(h, "%p %p %p %p %t %t %t %t %t %t %t", style.palette, style.background, style.filters, style.quantizer, style.flow, style.antialias, style.attachments, style.pixelSnap, style.legend, style.shadows, style.debugGrid)
+	writeFloat(phase)
+	writeFloat(style.opacity)
+	writeFloat(style.scale)
+	writeFloat(style.landGradient)
+	writeFloat(style.shadowAlpha)
+	writeFloat(float64(style.maxProcesses))
+	ids := make([]string, 0, len(style.highlights))
+	for id := range style.highlights {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		writeString(id)
+	}
+
+	if state == nil {
+		return h.Sum64()
+	}
+
+	for _, land := range state.Lands() {
+		writeString(land.ID)
+		writeString(land.Type)
+		writeFloat(land.X)
+		writeFloat(land.Y)
+		writeFloat(land.Elevation)
+	}
+	h.Write([]byte{1})
+	for _, proc := range state.Processes() {
+		writeString(proc.ID)
+		writeString(proc.Type)
+		writeString(proc.Status)
+		writeFloat(proc.X)
+		writeFloat(proc.Y)
+		writeFloat(proc.Z)
+		writeFloat(processAlpha(state, proc.ID))
+		writeFloat(processScale(state, proc.ID))
+	}
+	for _, tr := range processTrails(state) {
+		h.Write([]byte{3})
+		writeString(tr.typ)
+		writeFloat(tr.alpha)
+		for _, p := range tr.points {
+			writeString(p.LandID)
+			writeFloat(p.X)
+			writeFloat(p.Y)
+		}
+	}
+	if es, ok := state.(EdgeState); ok {
+		h.Write([]byte{2})
+		for _, e := range es.Edges() {
+			writeString(e.FromLandID)
+			writeString(e.ToLandID)
+			writeFloat(e.Flow)
+		}
+	}
+	return h.Sum64()
+}
+
+// recordRenderTime folds one frame's render time into the moving average
+// and applies AdaptiveScale. Scale only ever steps down, and only after a
+// full second of slow frames, so it cannot oscillate.
+func (r *Renderer) recordRenderTime(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.renderAvg == 0 {
+		r.renderAvg = d
+	} else {
+		r.renderAvg = (r.renderAvg*9 + d) / 10
+	}
+
+	if !r.opts.AdaptiveScale {
+		return
+	}
+	if r.actualFrameRateLocked() < 0.8*float64(r.opts.FrameRate) {
+		r.slowFrames++
+	} else {
+		r.slowFrames = 0
+	}
+	if r.slowFrames >= r.opts.FrameRate {
+		r.scale = max(r.scale*0.8, r.opts.Scale/4)
+		r.slowFrames = 0
+	}
+}
+
+// Tick returns the number of frames rendered so far
+func (r *Renderer) Tick() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tick
+}
+
+// ResetTick sets the tick and the animation clock back to zero so a fresh
+// recording starts at the same animation phase as any other. Animations
+// restart from the beginning of their cycle; the frame indicator, which
+// sweeps across every two seconds, snaps back to its start the same way
+// it does when it wraps.
+func (r *Renderer) ResetTick() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tick = 0
+	r.elapsed = 0
+}
+
+// Advance moves the animation clock forward by dt without rendering, the
+// time-based counterpart to the tick. Steps longer than a quarter second
+// are clamped so a stall doesn't make the scene jump.
+func (r *Renderer) Advance(dt time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.advanceLocked(dt)
+}
+
+func (r *Renderer) advanceLocked(dt time.Duration) {
+	r.elapsed += max(0, min(dt, maxAnimationStep))
+}
+
+// frameDuration is the time between frames at the configured frame rate
+func (r *Renderer) frameDuration() time.Duration {
+	return time.Second / time.Duration(r.opts.FrameRate)
+}
+
+// ActualFrameRate returns the frame rate the Frames loop can sustain, based
+// on a moving average of how long each frame takes to render. It never
+// exceeds the configured FrameRate and is 0 before any frame is rendered.
+func (r *Renderer) ActualFrameRate() float64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.actualFrameRateLocked()
+}
+
+func (r *Renderer) actualFrameRateLocked() float64 {
+	if r.renderAvg == 0 {
+		return 0
+	}
+	return min(float64(r.opts.FrameRate), float64(time.Second)/float64(r.renderAvg))
+}
+
+// RenderAt renders state as it looks tick frames in, at the configured
+// frame rate, using the software path only, so the output is reproducible
+// byte for byte regardless of GPU timing. It does not advance the
+// renderer's own tick or animation clock.
+func (r *Renderer) RenderAt(state State, tick int) image.Image {
+	return r.renderFrameSoftwareAt(state, animationPhase(time.Duration(tick)*r.frameDuration()))
+}
+
+// RenderInto renders a frame for state into dst using the software path,
+// avoiding a per-frame allocation. dst must match the configured frame
+// size. Like Render, it stores state and advances the tick.
+func (r *Renderer) RenderInto(state State, dst *image.RGBA) error {
+	want := image.Rect(0, 0, r.opts.Width, r.opts.Height)
+	if dst == nil || dst.Bounds() != want {
+		var got image.Rectangle
+		if dst != nil {
+			got = dst.Bounds()
+		}
+		return fmt.Errorf("destination bounds %v do not match frame size %dx%d", got, r.opts.Width, r.opts.Height)
+	}
+
+	r.mu.Lock()
+	r.setStateLocked(state)
+	r.tick++
+	r.advanceLocked(r.frameDuration())
+	phase := animationPhase(r.elapsed)
+	state = r.frameStateLocked()
+	closed := r.closed
+	r.mu.Unlock()
+
+	if closed {
+		return ErrRendererClosed
+	}
+
+	r.drawSoftware(dst, state, phase)
+	return nil
 }
 
 // renderFrameSoftware renders a frame using pure Go (no GPU)
 func (r *Renderer) renderFrameSoftware(state State) image.Image {
 	r.mu.RLock()
-	tick := r.tick
+	phase := animationPhase(r.elapsed)
 	r.mu.RUnlock()
 
+	return r.renderFrameSoftwareAt(state, phase)
+}
+
+// renderFrameSoftwareAt renders a frame using pure Go at the given
+// animation phase
+func (r *Renderer) renderFrameSoftwareAt(state State, phase float64) image.Image {
 	img := image.NewRGBA(image.Rect(0, 0, r.opts.Width, r.opts.Height))
+	r.drawSoftware(img, state, phase)
+	return img
+}
+
+// drawSoftware draws a full frame into img, which must match the frame size
+func (r *Renderer) drawSoftware(img *image.RGBA, state State, phase float64) {
+	r.mu.RLock()
+	style := r.styleLocked()
+	r.mu.RUnlock()
+	r.drawSceneSW(img, state, phase, style)
+	finishFrame(img, style)
+}
+
+// drawSceneSW draws the scene into img without running filters. img may be
+// a sub-image of a frame, which clips drawing to its bounds.
+func (r *Renderer) drawSceneSW(img *image.RGBA, state State, phase float64, style drawStyle) {
+	opacity, palette := style.opacity, style.palette
+	scale, gradient := style.scale, style.landGradient
 
-	// Draw dark background
-	bg := color.RGBA{20, 25, 30, 255}
+	// Draw background
+	bg := palette.Background
 	draw.Draw(img, img.Bounds(), &image.Uniform{bg}, image.Point{}, draw.Src)
+	if style.background != nil {
+		draw.Draw(img, img.Bounds(), style.background, img.Bounds().Min, draw.Over)
+	}
 
 	if state == nil {
-		return img
+		return
 	}
 
 	// Draw lands as grid
-	lands := state.Lands()
-	tileSize := int(64 * r.opts.Scale)
+	lands := sortLands(state.Lands())
+	tileSize := int(64 * scale)
 	startX := 100
 	startY := 100
 
-	for _, land := range lands {
-		x := startX + int(land.X)*tileSize
-		y := startY + int(land.Y)*tileSize
+	// Draw edges beneath the land tiles
+	half := (tileSize - 2) / 2
+	lifts := landLifts(lands, tileSize)
+	segments := resolveEdges(state, lands)
+	for _, seg := range segments {
+		x0 := startX + int(seg.from.X*float64(tileSize)) + half
+		y0 := startY + int(seg.from.Y*float64(tileSize)) - lifts[seg.from.ID] + half
+		x1 := startX + int(seg.to.X*float64(tileSize)) + half
+		y1 := startY + int(seg.to.Y*float64(tileSize)) - lifts[seg.to.ID] + half
+		c := fadeColor(edgeColor, seg.fade()*opacity)
+		drawLineSW(img, x0, y0, x1, y1, 2, c, r.opts.Width, r.opts.Height)
+	}
+
+	pulse := math.Mod(phase, 60) / 60.0
+	if pulse > 0.5 {
+		pulse = 1.0 - pulse
+	}
+	drawLands := func(dst *image.RGBA) {
+		for _, land := range lands {
+			lift := lifts[land.ID]
+			x := startX + int(land.X*float64(tileSize))
+			y := startY + int(land.Y*float64(tileSize)) - lift
+			if !onScreen(x, y, tileSize, tileSize+lift, r.opts.Width, r.opts.Height) {
+				continue
+			}
+
+			landColor := palette.LandColor(land.Type)
+			landColor.A = uint8(200 + pulse*55)
 
-		landColor := getLandColor(land.Type)
-		pulse := float64(tick%60) / 60.0
-		if pulse > 0.5 {
-			pulse = 1.0 - pulse
+			if lift > 0 {
+				side := landSide(x, y, tileSize-2, lift)
+				c := fadeColor(shadeColor(landColor, elevationShade), opacity)
+				fillRectSW(dst, side.Min.X, side.Min.Y, side.Dx(), side.Dy(), c, r.opts.Width, r.opts.Height)
+			}
+
+			if gradient == 0 {
+				fillRectSW(dst, x, y, tileSize-2, tileSize-2, fadeColor(landColor, opacity), r.opts.Width, r.opts.Height)
+				continue
+			}
+			// One row at a time, from lighter at the top to darker below
+			rows := tileSize - 2
+			for row := 0; row < rows; row++ {
+				t := 1 - 2*(float64(row)+0.5)/float64(rows)
+				c := fadeColor(shadeColor(landColor, gradient*t), opacity)
+				fillRectSW(dst, x, y+row, tileSize-2, 1, c, r.opts.Width, r.opts.Height)
+			}
 		}
-		landColor.A = uint8(200 + pulse*55)
+	}
+	r.parallelBands(img, drawLands)
+
+	// Highlighted lands glow on top of their neighbors
+	if len(style.highlights) > 0 {
+		glow := fadeColor(highlightColor, opacity*highlightAlpha(phase))
+		for _, land := range lands {
+			if !style.highlights[land.ID] {
+				continue
+			}
+			x := startX + int(land.X*float64(tileSize))
+			y := startY + int(land.Y*float64(tileSize)) - lifts[land.ID]
+			for _, strip := range highlightFrame(x, y, tileSize-2) {
+				fillRectSW(img, strip.Min.X, strip.Min.Y, strip.Dx(), strip.Dy(), glow, r.opts.Width, r.opts.Height)
+			}
+		}
+	}
 
-		fillRectSW(img, x, y, tileSize-2, tileSize-2, landColor, r.opts.Width, r.opts.Height)
+	if style.debugGrid {
+		lines, origin := gridRects(tileSize, r.opts.Width, r.opts.Height)
+		for _, line := range lines {
+			fillRectSW(img, line.Min.X, line.Min.Y, line.Dx(), line.Dy(), fadeColor(gridColor, opacity), r.opts.Width, r.opts.Height)
+		}
+		for _, bar := range origin {
+			fillRectSW(img, bar.Min.X, bar.Min.Y, bar.Dx(), bar.Dy(), fadeColor(gridOriginColor, opacity), r.opts.Width, r.opts.Height)
+		}
 	}
 
-	// Draw processes
-	processes := state.Processes()
-	for _, proc := range processes {
-		px := startX + int(proc.X)*tileSize + tileSize/2
-		py := startY + int(proc.Y)*tileSize + tileSize/2
+	processes, hidden := cullProcesses(sortProcesses(state.Processes()), style.maxProcesses)
+	offsets := fanOut(processes, tileSize)
+
+	// Shadows belong to the ground, beneath every sprite
+	if style.shadows {
+		for i, proc := range processes {
+			x := startX + int(proc.X*float64(tileSize)) + tileSize/2 + offsets[i].X
+			y := startY + int(proc.Y*float64(tileSize)) + tileSize/2 + offsets[i].Y - lifts[proc.LandID]
+			cx, cy, rx, ry := shadowEllipse(x, y, scaledRadius(8, processScale(state, proc.ID)))
+			c := fadeColor(shadowColor, opacity*style.shadowAlpha*processAlpha(state, proc.ID))
+			fillEllipseSW(img, cx, cy, rx, ry, c, style.antialias, r.opts.Width, r.opts.Height)
+		}
+	}
 
-		bounce := sin(float64(tick)/10.0+proc.X*0.5) * 3
+	// Trails fade toward the oldest position, beneath every sprite
+	for _, seg := range trailSegments(state, tileSize, lifts) {
+		c := fadeColor(palette.ProcessColor(seg.typ), seg.alpha*opacity)
+		drawLineSW(img, seg.x0, seg.y0, seg.x1, seg.y1, trailWidth, c, r.opts.Width, r.opts.Height)
+	}
+
+	// Flow dots travel on top of the tiles so they stay visible
+	if style.flow {
+		for _, seg := range segments {
+			for _, t := range seg.flowDots(phase) {
+				x := startX + int(lerp(seg.from.X, seg.to.X, t)*float64(tileSize)) + half
+				lift := lerp(float64(lifts[seg.from.ID]), float64(lifts[seg.to.ID]), t)
+				y := startY + int(lerp(seg.from.Y, seg.to.Y, t)*float64(tileSize)-lift) + half
+				fillCircleSW(img, x, y, 3, fadeColor(flowDotColor, opacity*seg.fade()), style.antialias, r.opts.Width, r.opts.Height)
+			}
+		}
+	}
+
+	// Attachment lines sit beneath every process sprite
+	if style.attachments {
+		byID := landsByID(lands)
+		for i, proc := range processes {
+			land, ok := byID[proc.LandID]
+			if !ok {
+				continue
+			}
+			lx := startX + int(land.X*float64(tileSize)) + half
+			ly := startY + int(land.Y*float64(tileSize)) - lifts[land.ID] + half
+			px := startX + int(proc.X*float64(tileSize)) + tileSize/2 + offsets[i].X
+			py := startY + int(proc.Y*float64(tileSize)) + tileSize/2 + offsets[i].Y - lifts[proc.LandID]
+			py += int(processBounce(phase, proc.X))
+			c := fadeColor(attachmentColor, opacity*processAlpha(state, proc.ID))
+			drawLineSW(img, lx, ly, px, py, 1, c, r.opts.Width, r.opts.Height)
+		}
+	}
+
+	for i, proc := range processes {
+		px := startX + int(proc.X*float64(tileSize)) + tileSize/2 + offsets[i].X
+		py := startY + int(proc.Y*float64(tileSize)) + tileSize/2 + offsets[i].Y - lifts[proc.LandID]
+
+		bounce := processBounce(phase, proc.X)
 		py += int(bounce)
+		if !onScreen(px-10, py-10, 21, 21, r.opts.Width, r.opts.Height) {
+			continue
+		}
 
-		procColor := getProcessColor(proc.Type)
-		fillCircleSW(img, px, py, 8, procColor, r.opts.Width, r.opts.Height)
+		alpha := opacity * processAlpha(state, proc.ID)
+		size := processScale(state, proc.ID)
+		if style.highlights[proc.ID] {
+			fillCircleSW(img, px, py, scaledRadius(13, size), fadeColor(highlightColor, alpha*highlightAlpha(phase)), style.antialias, r.opts.Width, r.opts.Height)
+		}
+		if outline, a, ok := palette.statusOutline(proc.Status, phase); ok {
+			fillCircleSW(img, px, py, scaledRadius(10, size), fadeColor(outline, alpha*a), style.antialias, r.opts.Width, r.opts.Height)
+		}
+		procColor := fadeColor(palette.ProcessColor(proc.Type), alpha)
+		fillCircleSW(img, px, py, scaledRadius(8, size), procColor, style.antialias, r.opts.Width, r.opts.Height)
+	}
+	if hidden > 0 {
+		c := fadeColor(overflowColor, opacity)
+		for _, rect := range overflowRects(hidden, r.opts.Width, r.opts.Height) {
+			fillRectSW(img, rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy(), c, r.opts.Width, r.opts.Height)
+		}
+	}
+	if style.legend {
+		for _, item := range legendLayout(state, palette, r.opts.Width, r.opts.Height) {
+			fillRectSW(img, item.rect.Min.X, item.rect.Min.Y, item.rect.Dx(), item.rect.Dy(), fadeColor(item.c, opacity), r.opts.Width, r.opts.Height)
+		}
 	}
 
 	// Frame indicator
-	frameX := 10 + (tick%60)*2
-	fillRectSW(img, frameX, 10, 4, 4, color.RGBA{100, 200, 100, 200}, r.opts.Width, r.opts.Height)
+	frameX := 10 + int(math.Mod(phase, 60))*2
+	fillRectSW(img, frameX, 10, 4, 4, fadeColor(color.RGBA{100, 200, 100, 200}, opacity), r.opts.Width, r.opts.Height)
+}
 
-	return img
+// parallelBands runs fn over horizontal bands of img, one per render
+// worker. Each band sees every draw call in the same order, clipped to its
+// own rows, so the result is identical to drawing serially.
+func (r *Renderer) parallelBands(img *image.RGBA, fn func(dst *image.RGBA)) {
+	workers := r.opts.RenderWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	bounds := img.Bounds()
+	workers = min(workers, bounds.Dy())
+	if workers <= 1 {
+		fn(img)
+		return
+	}
+
+	var wg sync.WaitGroup
+	bandHeight := (bounds.Dy() + workers - 1) / workers
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += bandHeight {
+		band := image.Rect(bounds.Min.X, y, bounds.Max.X, min(y+bandHeight, bounds.Max.Y))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(img.SubImage(band).(*image.RGBA))
+		}()
+	}
+	wg.Wait()
 }
 
-func getLandColor(landType string) color.RGBA {
-	switch landType {
-	case "mana":
-		return color.RGBA{80, 60, 120, 255}
-	case "forest":
-		return color.RGBA{40, 80, 50, 255}
-	case "water":
-		return color.RGBA{40, 60, 100, 255}
-	default:
-		return color.RGBA{60, 70, 60, 255}
+// onScreen reports whether the w x h box at (x, y) overlaps a frame of the
+// given size. Boxes that are only partially visible still count.
+func onScreen(x, y, w, h, width, height int) bool {
+	return x+w > 0 && y+h > 0 && x < width && y < height
+}
+
+// sortLands returns a copy of lands ordered back to front (by grid X+Y) so
+// that nearer tiles draw over farther ones. Ties are broken by ID to keep
+// the order stable. The state's own slice is left alone.
+func sortLands(lands []Land) []Land {
+	lands = slices.Clone(lands)
+	slices.SortStableFunc(lands, func(a, b Land) int {
+		if c := cmp.Compare(a.X+a.Y, b.X+b.Y); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return lands
+}
+
+// sortProcesses returns a copy of processes ordered by Z for drawing. The
+// sort is stable, so processes with equal Z keep the order the state
+// returned them in.
+func sortProcesses(processes []Process) []Process {
+	processes = slices.Clone(processes)
+	slices.SortStableFunc(processes, func(a, b Process) int {
+		return cmp.Compare(a.Z, b.Z)
+	})
+	return processes
+}
+
+// fanOut returns a pixel offset for each process so that processes sharing
+// a grid cell are spread on a small ring around the cell center instead of
+// overlapping. Positions within a cell are ordered by ID so they stay put
+// from frame to frame. A process alone in its cell gets no offset.
+func fanOut(processes []Process, tileSize int) []image.Point {
+	cells := make(map[image.Point][]int)
+	for i, proc := range processes {
+		cell := image.Pt(int(proc.X), int(proc.Y))
+		cells[cell] = append(cells[cell], i)
 	}
+
+	offsets := make([]image.Point, len(processes))
+	radius := float64(tileSize) / 4
+	for _, group := range cells {
+		if len(group) < 2 {
+			continue
+		}
+		sort.SliceStable(group, func(a, b int) bool {
+			return processes[group[a]].ID < processes[group[b]].ID
+		})
+		for slot, i := range group {
+			angle := 2*math.Pi*float64(slot)/float64(len(group)) - math.Pi/2
+			offsets[i] = image.Pt(int(math.Round(radius*math.Cos(angle))), int(math.Round(radius*math.Sin(angle))))
+		}
+	}
+	return offsets
 }
 
-func getProcessColor(procType string) color.RGBA {
-	switch procType {
-	case "tree":
-		return color.RGBA{60, 150, 60, 255}
-	case "nim":
-		return color.RGBA{200, 180, 100, 255}
-	case "mana":
-		return color.RGBA{150, 100, 200, 255}
-	default:
-		return color.RGBA{150, 150, 150, 255}
+// fadeColor scales c, a premultiplied color, by opacity, the same way
+// ColorScale.ScaleAlpha does on the GPU path
+func fadeColor(c color.RGBA, opacity float64) color.RGBA {
+	if opacity >= 1 {
+		return c
 	}
+	return mixRGBA(color.RGBA{}, c, opacity)
+}
+
+// blendRGBA composites the premultiplied color c over dst (source-over).
+// An opaque c simply replaces dst.
+func blendRGBA(dst, c color.RGBA) color.RGBA {
+	if c.A == 255 {
+		return c
+	}
+	k := 255 - uint32(c.A)
+	over := func(s, d uint8) uint8 {
+		return uint8(min(255, uint32(s)+(uint32(d)*k+127)/255))
+	}
+	return color.RGBA{over(c.R, dst.R), over(c.G, dst.G), over(c.B, dst.B), over(c.A, dst.A)}
 }
 
 // Software rendering helpers
 func fillRectSW(img *image.RGBA, x, y, w, h int, c color.RGBA, maxW, maxH int) {
-	for dy := 0; dy < h; dy++ {
-		for dx := 0; dx < w; dx++ {
-			px, py := x+dx, y+dy
-			if px >= 0 && px < maxW && py >= 0 && py < maxH {
-				img.SetRGBA(px, py, c)
+	rect := image.Rect(x, y, x+w, y+h).
+		Intersect(image.Rect(0, 0, maxW, maxH)).
+		Intersect(img.Bounds())
+	if rect.Empty() {
+		return
+	}
+
+	if c.A < 255 {
+		for py := rect.Min.Y; py < rect.Max.Y; py++ {
+			row := img.Pix[img.PixOffset(rect.Min.X, py):img.PixOffset(rect.Max.X, py)]
+			for i := 0; i < len(row); i += 4 {
+				out := blendRGBA(color.RGBA{row[i], row[i+1], row[i+2], row[i+3]}, c)
+				row[i], row[i+1], row[i+2], row[i+3] = out.R, out.G, out.B, out.A
 			}
 		}
+		return
+	}
+
+	// Opaque: fill the first row, then copy it down the remaining scanlines
+	first := img.Pix[img.PixOffset(rect.Min.X, rect.Min.Y):img.PixOffset(rect.Max.X, rect.Min.Y)]
+	for i := 0; i < len(first); i += 4 {
+		first[i], first[i+1], first[i+2], first[i+3] = c.R, c.G, c.B, c.A
+	}
+	for py := rect.Min.Y + 1; py < rect.Max.Y; py++ {
+		copy(img.Pix[img.PixOffset(rect.Min.X, py):], first)
 	}
 }
 
-func fillCircleSW(img *image.RGBA, cx, cy, radius int, c color.RGBA, maxW, maxH int) {
+func fillCircleSW(img *image.RGBA, cx, cy, radius int, c color.RGBA, antialias bool, maxW, maxH int) {
 	for y := -radius; y <= radius; y++ {
 		for x := -radius; x <= radius; x++ {
-			if x*x+y*y <= radius*radius {
-				px, py := cx+x, cy+y
-				if px >= 0 && px < maxW && py >= 0 && py < maxH {
-					img.SetRGBA(px, py, c)
-				}
+			px, py := cx+x, cy+y
+			if px < 0 || px >= maxW || py < 0 || py >= maxH {
+				continue
+			}
+			if cov := circleCoverage(x, y, radius, antialias); cov > 0 {
+				img.SetRGBA(px, py, blendRGBA(img.RGBAAt(px, py), fadeColor(c, cov)))
 			}
 		}
 	}
 }
 
+// circleCoverage returns how much of the pixel at offset (x, y) from a
+// circle's center pixel lies inside the circle, approximated from the
+// distance to the pixel center. Edge pixels get fractional coverage, which
+// anti-aliases the outline. Without antialias, coverage is rounded to 0
+// or 1 for a hard edge.
+func circleCoverage(x, y, radius int, antialias bool) float64 {
+	if radius <= 0 {
+		return 0
+	}
+	d := math.Sqrt(float64(x*x + y*y))
+	cov := max(0, min(1, float64(radius)+0.5-d))
+	if !antialias {
+		cov = math.Round(cov)
+	}
+	return cov
+}
+
+// shadeColor lightens c toward white for positive delta and darkens it
+// toward black for negative delta, leaving alpha alone. delta is in
+// [-1, 1], so channels stay within range.
+func shadeColor(c color.RGBA, delta float64) color.RGBA {
+	target := color.RGBA{A: c.A}
+	if delta > 0 {
+		target = color.RGBA{255, 255, 255, c.A}
+	}
+	return mixRGBA(c, target, math.Abs(delta))
+}
+
+// mixRGBA blends from a toward b by t, channel by channel
+func mixRGBA(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
+}
+
+// appendRect queues a filled rectangle for the next flushTriangles. Its
+// color blends vertically from top to bottom.
+func (g *ebitenGame) appendRect(dst *ebiten.Image, x, y, w, h float32, top, bottom color.RGBA, opacity float32) {
+	// Indices are 16-bit, so flush before they would overflow
+	if len(g.vertices)+4 > math.MaxUint16 {
+		g.flushTriangles(dst)
+	}
+
+	base := uint16(len(g.vertices))
+	corners := [4]struct {
+		x, y float32
+		c    color.RGBA
+	}{{x, y, top}, {x + w, y, top}, {x, y + h, bottom}, {x + w, y + h, bottom}}
+	for _, corner := range corners {
+		// Colors are premultiplied, matching drawFilledRect
+		g.vertices = append(g.vertices, ebiten.Vertex{
+			DstX: corner.x, DstY: corner.y,
+			SrcX: 1, SrcY: 1,
+			ColorR: float32(corner.c.R) / 255 * opacity,
+			ColorG: float32(corner.c.G) / 255 * opacity,
+			ColorB: float32(corner.c.B) / 255 * opacity,
+			ColorA: float32(corner.c.A) / 255 * opacity,
+		})
+	}
+	g.indices = append(g.indices, base, base+1, base+2, base+1, base+3, base+2)
+}
+
+// flushTriangles draws everything queued by appendRect in one call
+func (g *ebitenGame) flushTriangles(dst *ebiten.Image) {
+	if len(g.indices) == 0 {
+		return
+	}
+	op := &ebiten.DrawTrianglesOptions{
+		ColorScaleMode: ebiten.ColorScaleModePremultipliedAlpha,
+		AntiAlias:      g.antialias,
+	}
+	dst.DrawTriangles(g.vertices, g.indices, g.white, op)
+	g.vertices = g.vertices[:0]
+	g.indices = g.indices[:0]
+}
+
 // Ebiten drawing helpers
-func drawFilledRect(img *ebiten.Image, x, y, w, h float32, c color.RGBA) {
+func drawFilledRect(img *ebiten.Image, x, y, w, h float32, c color.RGBA, opacity float32) {
 	rect := image.NewRGBA(image.Rect(0, 0, int(w), int(h)))
 	draw.Draw(rect, rect.Bounds(), &image.Uniform{c}, image.Point{}, draw.Src)
 
 	ebitenRect := ebiten.NewImageFromImage(rect)
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Translate(float64(x), float64(y))
+	op.ColorScale.ScaleAlpha(opacity)
 	img.DrawImage(ebitenRect, op)
 }
 
-func drawFilledCircle(img *ebiten.Image, cx, cy, radius float32, c color.RGBA) {
+func drawFilledCircle(img *ebiten.Image, cx, cy, radius float32, c color.RGBA, opacity float32, antialias bool) {
 	r := int(radius)
 	size := r*2 + 1
 	circle := image.NewRGBA(image.Rect(0, 0, size, size))
 
 	for y := -r; y <= r; y++ {
 		for x := -r; x <= r; x++ {
-			if x*x+y*y <= r*r {
-				circle.SetRGBA(x+r, y+r, c)
+			// Premultiplied, so partial coverage scales every channel
+			if cov := circleCoverage(x, y, r, antialias); cov > 0 {
+				circle.SetRGBA(x+r, y+r, fadeColor(c, cov))
 			}
 		}
 	}
@@ -409,27 +1854,39 @@ func drawFilledCircle(img *ebiten.Image, cx, cy, radius float32, c color.RGBA) {
 	ebitenCircle := ebiten.NewImageFromImage(circle)
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Translate(float64(cx)-float64(r), float64(cy)-float64(r))
+	op.ColorScale.ScaleAlpha(opacity)
 	img.DrawImage(ebitenCircle, op)
 }
 
-// sin returns sine approximation
-func sin(x float64) float64 {
-	x = x - float64(int(x/(2*3.14159)))*2*3.14159
-	if x > 3.14159 {
-		x -= 2 * 3.14159
-	}
-	x2 := x * x
-	return x * (1 - x2/6 + x2*x2/120)
-}
-
 // Close closes the renderer
 func (r *Renderer) Close() error {
 	r.mu.Lock()
-	defer r.mu.Unlock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
 	r.closed = true
+	close(r.done)
+	gameDone := r.gameDone
+	r.mu.Unlock()
+
+	// Wait for the ebiten game to see the close and terminate
+	if gameDone != nil {
+		select {
+		case <-gameDone:
+		case <-time.After(time.Second):
+			return errors.New("timed out waiting for ebiten game to stop")
+		}
+	}
 	return nil
 }
 
+// DroppedFrames returns how many frames have been discarded because a
+// frame channel was full
+func (r *Renderer) DroppedFrames() int64 {
+	return r.dropped.Load()
+}
+
 // Size returns the current frame dimensions
 func (r *Renderer) Size() (width, height int) {
 	return r.opts.Width, r.opts.Height
@@ -0,0 +1,611 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRenderer(t testing.TB, opts Options) *Renderer {
+	t.Helper()
+	if opts.Width == 0 {
+		opts.Width, opts.Height = 320, 240
+	}
+	r, err := NewHeadless(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func TestRenderAtDeterministic(t *testing.T) {
+	r := newTestRenderer(t, Options{})
+	state := NewMockStateSeed(3)
+
+	a := r.RenderAt(state, 42).(*image.RGBA)
+	b := r.RenderAt(state, 42).(*image.RGBA)
+	if !bytes.Equal(a.Pix, b.Pix) {
+		t.Fatal("RenderAt gave different pixels for the same tick")
+	}
+	if r.Tick() != 0 {
+		t.Fatalf("RenderAt moved the tick to %d", r.Tick())
+	}
+}
+
+// drainResult reads frames until the channel closes and returns what the
+// error channel then reports
+func drainResult(t *testing.T, frames <-chan image.Image, errs <-chan error) error {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-frames:
+			if ok {
+				continue
+			}
+			return <-errs
+		case <-timeout:
+			t.Fatal("frames channel did not close")
+			return nil
+		}
+	}
+}
+
+func TestFramesResultReportsClose(t *testing.T) {
+	// Block makes the loop wait on the consumer, which Close must release
+	r := newTestRenderer(t, Options{FrameRate: 60, Backpressure: Block})
+	r.Update(NewMockStateSeed(1))
+
+	frames, errs := r.FramesResult(context.Background())
+	<-frames
+	time.Sleep(100 * time.Millisecond) // Let the buffer fill so the loop blocks
+	r.Close()
+
+	// The error arrives without reading the queued frames
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrRendererClosed) {
+			t.Fatalf("got %v, want ErrRendererClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the blocked frame loop did not stop on Close")
+	}
+	if err := drainResult(t, frames, errs); err != nil {
+		t.Fatalf("second error %v", err)
+	}
+}
+
+func TestFramesResultCancelIsClean(t *testing.T) {
+	r := newTestRenderer(t, Options{FrameRate: 60})
+	ctx, cancel := context.WithCancel(context.Background())
+	frames, errs := r.FramesResult(ctx)
+	<-frames
+	cancel()
+
+	if err := drainResult(t, frames, errs); err != nil {
+		t.Fatalf("got %v after cancel, want nil", err)
+	}
+}
+
+func TestNewRejectsNegativeWidth(t *testing.T) {
+	_, err := NewHeadless(Options{Width: -5, Height: 240})
+	if err == nil {
+		t.Fatal("expected an error for a negative width")
+	}
+	if !errors.Is(err, ErrInvalidOptions) {
+		t.Errorf("error %v does not wrap ErrInvalidOptions", err)
+	}
+	if !strings.Contains(err.Error(), "Width -5") {
+		t.Errorf("error %q does not name the width", err)
+	}
+}
+
+func TestHeadlessNoGoroutineLeak(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	r, err := NewHeadless(Options{Width: 320, Height: 240})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Render(NewMockStateSeed(1)) == nil {
+		t.Fatal("Render returned no frame")
+	}
+	done := make(chan struct{})
+	go func() {
+		r.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Close blocked")
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines after Close, %d before", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestOffscreenLandNotDrawn(t *testing.T) {
+	r := newTestRenderer(t, Options{Width: 320, Height: 240})
+	empty := FrameDigest(r.RenderAt(&staticState{}, 0))
+
+	far := &staticState{lands: []Land{{ID: "far", X: 100, Y: 100, Type: "forest"}}}
+	if FrameDigest(r.RenderAt(far, 0)) != empty {
+		t.Fatal("an off-screen land changed the frame")
+	}
+
+	// A tile straddling the right edge is still drawn
+	edge := &staticState{lands: []Land{{ID: "edge", X: 3, Y: 0, Type: "forest"}}}
+	if FrameDigest(r.RenderAt(edge, 0)) == empty {
+		t.Fatal("a partially visible land was culled")
+	}
+}
+
+// gridLands returns an n x n grid of lands
+func gridLands(n int) []Land {
+	lands := make([]Land, 0, n*n)
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			lands = append(lands, Land{ID: generateID(y*n + x), X: float64(x), Y: float64(y), Type: "forest"})
+		}
+	}
+	return lands
+}
+
+// benchmarkLandLoop draws a 100x100 grid of gradient tiles into a frame
+// that shows only a corner of it, the way the software path does, with or
+// without the onScreen check
+func benchmarkLandLoop(b *testing.B, cull bool) {
+	const width, height, tileSize = 640, 480, 64
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	lands := gridLands(100)
+	c := DefaultPalette().LandColor("forest")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, land := range lands {
+			x := 100 + int(land.X)*tileSize
+			y := 100 + int(land.Y)*tileSize
+			if cull && !onScreen(x, y, tileSize, tileSize, width, height) {
+				continue
+			}
+			for row := 0; row < tileSize-2; row++ {
+				fillRectSW(img, x, y+row, tileSize-2, 1, c, width, height)
+			}
+		}
+	}
+}
+
+func BenchmarkLandGridCulled(b *testing.B)   { benchmarkLandLoop(b, true) }
+func BenchmarkLandGridUnculled(b *testing.B) { benchmarkLandLoop(b, false) }
+
+func TestRenderWorkersMatchSerial(t *testing.T) {
+	lands := gridLands(6)
+	// Overlapping tiles must still come out in the same order
+	lands = append(lands, Land{ID: "overlap", X: 2, Y: 2, Type: "mana"})
+	state := &staticState{lands: lands, processes: NewMockStateSeed(5).Processes()}
+
+	serial := newTestRenderer(t, Options{RenderWorkers: 1})
+	want := FrameDigest(serial.RenderAt(state, 7))
+	for _, workers := range []int{2, 3, 8} {
+		r := newTestRenderer(t, Options{RenderWorkers: workers})
+		if got := FrameDigest(r.RenderAt(state, 7)); got != want {
+			t.Errorf("%d workers differ from the serial path", workers)
+		}
+	}
+}
+
+func benchmarkRenderWorkers(b *testing.B, workers int) {
+	r := newTestRenderer(b, Options{Width: 1920, Height: 1080, RenderWorkers: workers})
+	r.SetLandGradient(0.2)
+	state := &staticState{lands: gridLands(30)}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.RenderAt(state, i)
+	}
+}
+
+func BenchmarkRenderSingleWorker(b *testing.B) { benchmarkRenderWorkers(b, 1) }
+func BenchmarkRenderMultiWorker(b *testing.B)  { benchmarkRenderWorkers(b, 0) }
+
+// naiveFillRect is the per-pixel loop fillRectSW's fast path replaced
+func naiveFillRect(img *image.RGBA, x, y, w, h int, c color.RGBA, maxW, maxH int) {
+	for py := y; py < y+h; py++ {
+		for px := x; px < x+w; px++ {
+			if px < 0 || py < 0 || px >= maxW || py >= maxH {
+				continue
+			}
+			img.SetRGBA(px, py, blendRGBA(img.RGBAAt(px, py), c))
+		}
+	}
+}
+
+func TestFillRectSWMatchesNaive(t *testing.T) {
+	rects := []image.Rectangle{
+		image.Rect(10, 10, 50, 40),     // Inside
+		image.Rect(-20, 5, 30, 25),     // Off the left
+		image.Rect(60, -15, 90, 10),    // Off the top
+		image.Rect(300, 200, 400, 300), // Off the bottom right
+		image.Rect(500, 500, 520, 520), // Fully off
+	}
+	for _, c := range []color.RGBA{{200, 100, 50, 255}, {100, 50, 20, 128}} {
+		fast := image.NewRGBA(image.Rect(0, 0, 320, 240))
+		naive := image.NewRGBA(fast.Rect)
+		for _, rect := range rects {
+			fillRectSW(fast, rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy(), c, 320, 240)
+			naiveFillRect(naive, rect.Min.X, rect.Min.Y, rect.Dx(), rect.Dy(), c, 320, 240)
+		}
+		if !bytes.Equal(fast.Pix, naive.Pix) {
+			t.Errorf("fillRectSW with alpha %d differs from the naive loop", c.A)
+		}
+	}
+}
+
+func benchmarkFillRect(b *testing.B, fill func(*image.RGBA, int, int, int, int, color.RGBA, int, int)) {
+	img := image.NewRGBA(image.Rect(0, 0, 1920, 1080))
+	c := color.RGBA{40, 80, 50, 255}
+	for i := 0; i < b.N; i++ {
+		fill(img, -10, -10, 1940, 1100, c, 1920, 1080)
+	}
+}
+
+func BenchmarkFillRectSW(b *testing.B)    { benchmarkFillRect(b, fillRectSW) }
+func BenchmarkFillRectNaive(b *testing.B) { benchmarkFillRect(b, naiveFillRect) }
+
+// taylorSin is the truncated Taylor series processBounce used to rely on,
+// kept here to benchmark against
+func taylorSin(x float64) float64 {
+	x = x - float64(int(x/(2*3.14159)))*2*3.14159
+	if x > 3.14159 {
+		x -= 2 * 3.14159
+	}
+	x2 := x * x
+	return x * (1 - x2/6 + x2*x2/120)
+}
+
+func TestProcessBounceContinuousAcrossWrap(t *testing.T) {
+	// The bounce moves at most 0.3px per animation step, including around
+	// each point where phase/10 wraps a full period, however long the run
+	const maxStep = 0.3 + 1e-9
+	for _, period := range []float64{1, 2, 1000, 1e6} {
+		wrap := period * 2 * math.Pi * 10
+		for phase := math.Floor(wrap) - 5; phase < wrap+5; phase++ {
+			if d := math.Abs(processBounce(phase+1, 0) - processBounce(phase, 0)); d > maxStep {
+				t.Fatalf("bounce jumps %.3fpx between phase %v and %v", d, phase, phase+1)
+			}
+		}
+	}
+}
+
+// benchmarkBounce evaluates sin the way processBounce does over one full
+// bounce loop for 100 processes
+func benchmarkBounce(b *testing.B, sin func(float64) float64) {
+	loop := int(math.Ceil(2 * math.Pi * 10))
+	var sum float64
+	for i := 0; i < b.N; i++ {
+		for phase := 0; phase < loop; phase++ {
+			for x := 0; x < 100; x++ {
+				sum += sin(float64(phase)/10.0+float64(x)*0.5) * 3
+			}
+		}
+	}
+	_ = sum
+}
+
+func BenchmarkBounceTaylorSin(b *testing.B) { benchmarkBounce(b, taylorSin) }
+func BenchmarkBounceMathSin(b *testing.B)   { benchmarkBounce(b, math.Sin) }
+
+func TestRenderIntoReusesBuffer(t *testing.T) {
+	r := newTestRenderer(t, Options{})
+	ref := newTestRenderer(t, Options{})
+	state := NewMockStateSeed(4)
+
+	// Both renderers advance their tick the same way, so each frame drawn
+	// into the shared buffer must match a freshly allocated one
+	dst := image.NewRGBA(image.Rect(0, 0, 320, 240))
+	for i := 0; i < 2; i++ {
+		if err := r.RenderInto(state, dst); err != nil {
+			t.Fatal(err)
+		}
+		want := ref.Render(state).(*image.RGBA)
+		if !bytes.Equal(dst.Pix, want.Pix) {
+			t.Fatalf("frame %d drawn into the reused buffer differs from Render", i)
+		}
+		state.Randomize()
+	}
+
+	err := r.RenderInto(state, image.NewRGBA(image.Rect(0, 0, 100, 100)))
+	if err == nil || !strings.Contains(err.Error(), "320x240") {
+		t.Errorf("RenderInto with a wrong-sized buffer returned %v, want an error naming 320x240", err)
+	}
+}
+
+func TestNewWithGPUDoesNotStartGame(t *testing.T) {
+	start := time.Now()
+	r, err := New(Options{Width: 320, Height: 240, UseGPU: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if d := time.Since(start); d > 50*time.Millisecond {
+		t.Errorf("New took %v, want well under 100ms", d)
+	}
+
+	// The game starts on the first GPU render, not in New
+	r.mu.RLock()
+	game := r.game
+	r.mu.RUnlock()
+	if game != nil {
+		t.Error("New started the ebiten game")
+	}
+}
+
+func TestTranslucentFillsBlendOverBackground(t *testing.T) {
+	bg := color.RGBA{20, 40, 60, 255}
+	half := color.RGBA{100, 50, 0, 128} // Premultiplied, 50% alpha
+	want := color.RGBA{110, 70, 30, 255}
+
+	img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	fillRectSW(img, 0, 0, 40, 20, bg, 40, 20)
+	fillCircleSW(img, 10, 10, 5, half, true, 40, 20)
+	fillRectSW(img, 25, 5, 10, 10, half, 40, 20)
+	if got := img.RGBAAt(10, 10); got != want {
+		t.Errorf("circle center is %v, want %v", got, want)
+	}
+	if got := img.RGBAAt(30, 10); got != want {
+		t.Errorf("rectangle is %v, want %v", got, want)
+	}
+
+	// Opaque colors overwrite
+	opaque := color.RGBA{200, 100, 0, 255}
+	fillCircleSW(img, 10, 10, 5, opaque, true, 40, 20)
+	if got := img.RGBAAt(10, 10); got != opaque {
+		t.Errorf("opaque circle center is %v, want %v", got, opaque)
+	}
+}
+
+func TestAntialiasOffDrawsHardEdges(t *testing.T) {
+	r := newTestRenderer(t, Options{})
+	state := &staticState{processes: []Process{{ID: "p", Type: "tree"}}}
+	bg, tree := DefaultPalette().Background, DefaultPalette().ProcessColor("tree")
+
+	// Count pixels around the sprite at (132, 132) that are neither the
+	// background nor the sprite color
+	blended := func() int {
+		img := r.RenderAt(state, 0).(*image.RGBA)
+		n := 0
+		for y := 120; y <= 144; y++ {
+			for x := 120; x <= 144; x++ {
+				if c := img.RGBAAt(x, y); c != bg && c != tree {
+					n++
+				}
+			}
+		}
+		return n
+	}
+
+	if blended() == 0 {
+		t.Fatal("anti-aliased sprite has no blended edge pixels")
+	}
+	r.SetAntialias(false)
+	if n := blended(); n != 0 {
+		t.Errorf("sprite without anti-aliasing has %d blended edge pixels", n)
+	}
+}
+
+func TestFillCircleSWAntialiasedEdge(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	fillCircleSW(img, 20, 20, 8, white, true, 40, 40)
+
+	// Pixels are covered by how far their center lies inside radius + 0.5
+	for _, tc := range []struct {
+		x, y int
+		want uint8
+	}{
+		{20, 20, 255}, // Center
+		{27, 20, 255}, // 7 from the center, fully inside
+		{28, 20, 128}, // On the radius, half covered
+		{12, 20, 128},
+		{20, 28, 128},
+		{29, 20, 0}, // Outside
+	} {
+		if got := img.RGBAAt(tc.x, tc.y).A; int(got) < int(tc.want)-1 || int(got) > int(tc.want)+1 {
+			t.Errorf("alpha at (%d, %d) = %d, want %d", tc.x, tc.y, got, tc.want)
+		}
+	}
+
+	// Tiny circles stay within their radius
+	for _, radius := range []int{0, 1} {
+		img := image.NewRGBA(image.Rect(0, 0, 5, 5))
+		fillCircleSW(img, 2, 2, radius, white, true, 5, 5)
+		for y := 0; y < 5; y++ {
+			for x := 0; x < 5; x++ {
+				inside := max(x-2, 2-x) <= radius && max(y-2, 2-y) <= radius && radius > 0
+				if a := img.RGBAAt(x, y).A; a != 0 && !inside {
+					t.Errorf("radius %d drew alpha %d at (%d, %d)", radius, a, x, y)
+				}
+			}
+		}
+		if a := img.RGBAAt(2, 2).A; radius == 1 && a != 255 {
+			t.Errorf("radius 1 center alpha = %d, want 255", a)
+		}
+	}
+}
+
+func TestNearerLandDrawnOverFartherOne(t *testing.T) {
+	r := newTestRenderer(t, Options{})
+	// The nearer land comes first in the state and is raised by 16 pixels,
+	// so its tile overlaps the bottom of the farther one
+	state := &staticState{lands: []Land{
+		{ID: "near", X: 0, Y: 1, Type: "mana", Elevation: 1},
+		{ID: "far", X: 0, Y: 0, Type: "forest"},
+	}}
+	got := r.RenderAt(state, 0).(*image.RGBA).RGBAAt(131, 155)
+	if !(got.B > got.G) {
+		t.Errorf("overlap pixel is %v, want the nearer mana tile on top", got)
+	}
+
+	// Ties in depth are ordered by ID
+	lands := sortLands([]Land{{ID: "b", X: 1}, {ID: "a", Y: 1}, {ID: "c"}})
+	if ids := [3]string{lands[0].ID, lands[1].ID, lands[2].ID}; ids != [3]string{"c", "a", "b"} {
+		t.Errorf("sorted lands %v, want c, a, b", ids)
+	}
+}
+
+func TestProcessesSharingCellFanOut(t *testing.T) {
+	r := newTestRenderer(t, Options{})
+	p := DefaultPalette()
+	state := &staticState{processes: []Process{
+		{ID: "c", Type: "mana"},
+		{ID: "a", Type: "tree"},
+		{ID: "b", Type: "nim"},
+	}}
+	img := r.RenderAt(state, 0).(*image.RGBA)
+
+	// Slots go clockwise from the top of a ring a quarter tile wide around
+	// the cell center at (132, 132), in ID order
+	for _, tc := range []struct {
+		pt  image.Point
+		typ string
+	}{
+		{image.Pt(132, 116), "tree"}, // a
+		{image.Pt(146, 140), "nim"},  // b
+		{image.Pt(118, 140), "mana"}, // c
+	} {
+		if got := img.RGBAAt(tc.pt.X, tc.pt.Y); got != p.ProcessColor(tc.typ) {
+			t.Errorf("pixel at %v is %v, want the %s process", tc.pt, got, tc.typ)
+		}
+	}
+
+	// A process alone in its cell stays at the center
+	if off := fanOut([]Process{{ID: "a"}, {ID: "b", X: 1}}, 64); off[0] != (image.Point{}) || off[1] != (image.Point{}) {
+		t.Errorf("lone processes offset by %v", off)
+	}
+}
+
+func TestAppendRectBatchesQuads(t *testing.T) {
+	g := &ebitenGame{}
+	top, bottom := color.RGBA{200, 100, 0, 255}, color.RGBA{100, 50, 0, 255}
+	g.appendRect(nil, 10, 20, 62, 62, top, bottom, 0.5)
+	g.appendRect(nil, 74, 20, 62, 62, top, bottom, 1)
+
+	if len(g.vertices) != 8 || len(g.indices) != 12 {
+		t.Fatalf("queued %d vertices and %d indices for two tiles, want 8 and 12", len(g.vertices), len(g.indices))
+	}
+	// The second quad's triangles index its own vertices
+	if g.indices[6] != 4 {
+		t.Errorf("second tile starts at index %d, want 4", g.indices[6])
+	}
+
+	// Corners run top-left, top-right, bottom-left, bottom-right, with
+	// premultiplied colors scaled by the opacity
+	v := g.vertices
+	if v[0].DstX != 10 || v[0].DstY != 20 || v[3].DstX != 72 || v[3].DstY != 82 {
+		t.Errorf("first tile spans (%v, %v) to (%v, %v), want (10, 20) to (72, 82)", v[0].DstX, v[0].DstY, v[3].DstX, v[3].DstY)
+	}
+	approx := func(a, b float32) bool { return math.Abs(float64(a-b)) < 1e-6 }
+	if !approx(v[0].ColorR, 200.0/255*0.5) || !approx(v[2].ColorR, 100.0/255*0.5) || !approx(v[0].ColorA, 0.5) {
+		t.Errorf("first tile colors R %v at the top and %v at the bottom, alpha %v", v[0].ColorR, v[2].ColorR, v[0].ColorA)
+	}
+}
+
+// BenchmarkAppendLandGrid queues every tile of a 100x100 grid the way the
+// GPU path does, reporting how many DrawTriangles calls that takes.
+// Drawing needs a running ebiten game, so the batch is only counted.
+func BenchmarkAppendLandGrid(b *testing.B) {
+	lands := gridLands(100)
+	g := &ebitenGame{}
+	c := DefaultPalette().LandColor("forest")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	draws := 0
+	for i := 0; i < b.N; i++ {
+		for _, land := range lands {
+			g.appendRect(nil, float32(100+64*land.X), float32(100+64*land.Y), 62, 62, c, c, 1)
+		}
+		// appendRect flushes whenever a batch would pass 65535 vertices
+		draws = (len(g.vertices) + math.MaxUint16 - 1) / math.MaxUint16
+		g.vertices, g.indices = g.vertices[:0], g.indices[:0]
+	}
+	b.ReportMetric(float64(draws), "draws/op")
+}
+
+func TestLandGradientLightensTopRow(t *testing.T) {
+	r := newTestRenderer(t, Options{})
+	state := &staticState{lands: []Land{{ID: "a", Type: "forest"}}}
+	rows := func() (top, bottom color.RGBA) {
+		img := r.RenderAt(state, 0).(*image.RGBA)
+		return img.RGBAAt(131, 100), img.RGBAAt(131, 161)
+	}
+
+	if top, bottom := rows(); top != bottom {
+		t.Fatalf("flat tile has top %v and bottom %v", top, bottom)
+	}
+	// Strength is clamped to 1, so channels saturate instead of wrapping
+	for _, strength := range []float64{0.3, 5} {
+		r.SetLandGradient(strength)
+		top, bottom := rows()
+		if top.R <= bottom.R || top.G <= bottom.G || top.B <= bottom.B {
+			t.Errorf("strength %v: top row %v is not lighter than bottom row %v", strength, top, bottom)
+		}
+	}
+}
+
+func TestFramesWithNilStateDrawBackground(t *testing.T) {
+	clock := newFakeClock()
+	r := newTestRenderer(t, Options{Clock: clock})
+	bg := DefaultPalette().Background
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	frames := r.Frames(ctx)
+	ticker := <-clock.tickers
+	next := func() *image.RGBA {
+		t.Helper()
+		clock.fire(ticker)
+		select {
+		case img := <-frames:
+			return img.(*image.RGBA)
+		case <-time.After(time.Second):
+			t.Fatal("no frame after a tick")
+			return nil
+		}
+	}
+
+	tile := func(img *image.RGBA) color.RGBA {
+		if img.Bounds() != image.Rect(0, 0, 320, 240) {
+			t.Fatalf("frame is %v, want 320x240", img.Bounds())
+		}
+		return img.RGBAAt(131, 131)
+	}
+
+	// Before any Update, and after the state goes back to nil, only the
+	// background is drawn
+	if got := tile(next()); got != bg {
+		t.Errorf("frame before any Update has %v where a tile would be, want the background", got)
+	}
+	r.Update(NewMockStateSeed(1))
+	if got := tile(next()); got == bg {
+		t.Error("frame with a state drew no tile")
+	}
+	r.Update(nil)
+	if got := tile(next()); got != bg {
+		t.Errorf("frame after Update(nil) has %v where a tile was, want the background", got)
+	}
+}
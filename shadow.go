@@ -0,0 +1,85 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// shadowColor is the color of process shadows before their alpha applies
+var shadowColor = color.RGBA{0, 0, 0, 255}
+
+// defaultShadowAlpha is the shadow opacity until SetShadowAlpha is called
+const defaultShadowAlpha = 0.35
+
+// SetShadowsEnabled turns on a flattened dark ellipse on the ground under
+// each process, as wide as the sprite, which bobs above it. Shadows are
+// drawn with the lands, before any sprite, so no sprite covers another's
+// shadow out of order.
+func (r *Renderer) SetShadowsEnabled(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shadows = enabled
+}
+
+// SetShadowAlpha sets the opacity of process shadows (default 0.35).
+// Values are clamped to [0, 1].
+func (r *Renderer) SetShadowAlpha(a float64) {
+	a = max(0, min(1, a))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shadowAlpha = a
+}
+
+// shadowEllipse returns the center and radii of the shadow of a sprite of
+// the given radius whose center, without bounce, is at x, y. It sits where
+// the resting sprite touches the ground.
+func shadowEllipse(x, y, radius int) (cx, cy, rx, ry int) {
+	return x, y + radius, radius, max(1, radius/3)
+}
+
+// ellipseCoverage is circleCoverage for an ellipse with radii rx and ry
+func ellipseCoverage(x, y, rx, ry int, antialias bool) float64 {
+	if rx <= 0 || ry <= 0 {
+		return 0
+	}
+	d := math.Hypot(float64(x)/float64(rx), float64(y)/float64(ry))
+	cov := max(0, min(1, (1-d)*float64(min(rx, ry))+0.5))
+	if !antialias {
+		cov = math.Round(cov)
+	}
+	return cov
+}
+
+func fillEllipseSW(img *image.RGBA, cx, cy, rx, ry int, c color.RGBA, antialias bool, maxW, maxH int) {
+	for y := -ry; y <= ry; y++ {
+		for x := -rx; x <= rx; x++ {
+			px, py := cx+x, cy+y
+			if px < 0 || px >= maxW || py < 0 || py >= maxH {
+				continue
+			}
+			if cov := ellipseCoverage(x, y, rx, ry, antialias); cov > 0 {
+				img.SetRGBA(px, py, blendRGBA(img.RGBAAt(px, py), fadeColor(c, cov)))
+			}
+		}
+	}
+}
+
+func drawFilledEllipse(img *ebiten.Image, cx, cy float32, rx, ry int, c color.RGBA, opacity float32, antialias bool) {
+	ellipse := image.NewRGBA(image.Rect(0, 0, 2*rx+1, 2*ry+1))
+	for y := -ry; y <= ry; y++ {
+		for x := -rx; x <= rx; x++ {
+			if cov := ellipseCoverage(x, y, rx, ry, antialias); cov > 0 {
+				ellipse.SetRGBA(x+rx, y+ry, fadeColor(c, cov))
+			}
+		}
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(cx)-float64(rx), float64(cy)-float64(ry))
+	op.ColorScale.ScaleAlpha(opacity)
+	img.DrawImage(ebiten.NewImageFromImage(ellipse), op)
+}
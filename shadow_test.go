@@ -0,0 +1,29 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"testing"
+)
+
+func TestShadowDarkensGroundUnderSprite(t *testing.T) {
+	r := newTestRenderer(t, Options{Width: 320, Height: 240})
+	state := &staticState{processes: []Process{{ID: "p", Type: "tree"}}}
+	bg := DefaultPalette().Background
+
+	// The sprite's center rests at (132, 132) with a radius of 8, so the
+	// shadow's lower half is below the sprite's ground point
+	below := image.Pt(132, 142)
+	if got := r.RenderAt(state, 0).(*image.RGBA).RGBAAt(below.X, below.Y); got != bg {
+		t.Fatalf("pixel below the sprite is %v without shadows, want the background %v", got, bg)
+	}
+
+	r.SetShadowsEnabled(true)
+	img := r.RenderAt(state, 0).(*image.RGBA)
+	got := img.RGBAAt(below.X, below.Y)
+	if got.R >= bg.R || got.G >= bg.G || got.B >= bg.B {
+		t.Errorf("pixel below the sprite is %v with shadows, want darker than %v", got, bg)
+	}
+	if side := img.RGBAAt(132, 150); side != bg {
+		t.Errorf("pixel past the shadow is %v, want the background %v", side, bg)
+	}
+}
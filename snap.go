@@ -0,0 +1,21 @@
+package nimsforestsprites
+
+import "math"
+
+// SetPixelSnap makes the GPU path round every position it draws at down to
+// a whole pixel, bounce included, so slow motion in pixel-art output steps
+// cleanly instead of shimmering across pixel boundaries. The software path
+// always draws on whole pixels and is unaffected.
+func (r *Renderer) SetPixelSnap(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pixelSnap = enabled
+}
+
+// snapPixel rounds a GPU coordinate down to a whole pixel when snap is set
+func snapPixel(v float32, snap bool) float32 {
+	if !snap {
+		return v
+	}
+	return float32(math.Floor(float64(v)))
+}
@@ -0,0 +1,68 @@
+package nimsforestsprites
+
+import (
+	"errors"
+	"image"
+	"image/draw"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+)
+
+// Snapshot returns a copy of the most recent frame produced by Render,
+// Frames, or FramesWithInfo without advancing the tick or touching state.
+// It returns false if no frame has been produced yet. After Close it still
+// returns the final frame. With SetCaptureBuffer the retained frame
+// shares the reused buffer, so the copy may reflect a later capture.
+func (r *Renderer) Snapshot() (image.Image, bool) {
+	r.mu.RLock()
+	frame := r.lastFrame
+	r.mu.RUnlock()
+
+	if frame == nil {
+		return nil, false
+	}
+	b := frame.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(img, img.Bounds(), frame, b.Min, draw.Src)
+	return img, true
+}
+
+// SaveSnapshotPNG writes the most recent frame to path as a PNG. It returns
+// an error without creating the file if no frame has been produced yet.
+func (r *Renderer) SaveSnapshotPNG(path string) error {
+	img, ok := r.Snapshot()
+	if !ok {
+		return errors.New("no frame rendered yet")
+	}
+	return writePNG(path, img)
+}
+
+// watchSnapshotSignal saves a timestamped snapshot each time
+// Options.SnapshotSignal arrives, until the renderer is closed
+func (r *Renderer) watchSnapshotSignal() {
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, r.opts.SnapshotSignal)
+
+	dir := r.opts.SnapshotDir
+	if dir == "" {
+		dir = "."
+	}
+
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-r.done:
+				return
+			case <-sigCh:
+				name := "snapshot-" + time.Now().Format("20060102-150405.000") + ".png"
+				if err := r.SaveSnapshotPNG(filepath.Join(dir, name)); err != nil {
+					log.Printf("nimsforestsprites: snapshot failed: %v", err)
+				}
+			}
+		}
+	}()
+}
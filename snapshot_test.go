@@ -0,0 +1,77 @@
+package nimsforestsprites
+
+import (
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshotReturnsLastFrameFromFrames(t *testing.T) {
+	clock := newFakeClock()
+	r := newTestRenderer(t, Options{Width: 200, Height: 150, Clock: clock})
+	r.Update(NewMockStateSeed(1))
+	if _, ok := r.Snapshot(); ok {
+		t.Fatal("Snapshot reported a frame before any was produced")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	frames := r.Frames(ctx)
+	ticker := <-clock.tickers
+	for i := 0; i < 2; i++ {
+		clock.fire(ticker)
+		select {
+		case <-frames:
+		case <-time.After(time.Second):
+			t.Fatal("no frame after a tick")
+		}
+	}
+
+	img, ok := r.Snapshot()
+	if !ok || img.Bounds() != image.Rect(0, 0, 200, 150) {
+		t.Fatalf("Snapshot = %v, %v, want a 200x150 frame", img, ok)
+	}
+	r.mu.RLock()
+	tick := r.tick
+	r.mu.RUnlock()
+	if tick != 2 {
+		t.Errorf("tick is %d after two frames and a Snapshot, want 2", tick)
+	}
+
+	r.Close()
+	if img, ok := r.Snapshot(); !ok || img == nil {
+		t.Error("Snapshot after Close returned no frame")
+	}
+}
+
+func TestSaveSnapshotPNGWritesDecodablePNG(t *testing.T) {
+	r := newTestRenderer(t, Options{Width: 200, Height: 150})
+	path := filepath.Join(t.TempDir(), "snap.png")
+	if err := r.SaveSnapshotPNG(path); err == nil {
+		t.Fatal("SaveSnapshotPNG succeeded before any frame was rendered")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("file exists after a failed snapshot: %v", err)
+	}
+
+	r.Render(NewMockStateSeed(1))
+	if err := r.SaveSnapshotPNG(path); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 200, 150) {
+		t.Errorf("snapshot is %v, want 200x150", img.Bounds())
+	}
+}
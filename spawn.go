@@ -0,0 +1,196 @@
+package nimsforestsprites
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// defaultSpawnDuration is how long spawn and despawn animations run when
+// SetSpawnAnimation is not given a duration
+const defaultSpawnDuration = 300 * time.Millisecond
+
+// SetSpawnAnimation makes processes that appear in an update pop in,
+// growing from nothing to full size with a slight overshoot, and ones that
+// disappear shrink away while fading out, over duration (default 300ms).
+// Processes are matched by ID across Update calls, timed on Options.Clock
+// like interpolation. The processes already showing when it is enabled
+// don't animate. This is separate from the fade Options.Interpolate
+// applies, and both can be on at once.
+func (r *Renderer) SetSpawnAnimation(enabled bool, duration time.Duration) {
+	if duration <= 0 {
+		duration = defaultSpawnDuration
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !enabled {
+		r.spawn = nil
+		return
+	}
+	r.spawn = &spawnTracker{duration: duration}
+	if r.state != nil {
+		r.spawn.update(r.state, r.interpolationTimeLocked())
+	}
+}
+
+// spawnTracker records when processes appear and disappear between
+// updates, so frames can animate them
+type spawnTracker struct {
+	duration time.Duration
+	seeded   bool      // Set by the first update, whose processes don't animate
+	last     []Process // Processes of the latest update
+
+	appeared map[string]time.Time
+	vanished map[string]vanishedProcess
+}
+
+// vanishedProcess is a process missing from the latest update, kept so it
+// can still be drawn shrinking away
+type vanishedProcess struct {
+	proc Process
+	at   time.Time
+}
+
+// update compares state with the previous update. Animations that have
+// finished by now are dropped.
+func (t *spawnTracker) update(state State, now time.Time) {
+	var procs []Process
+	if state != nil {
+		procs = state.Processes()
+	}
+	if t.appeared == nil {
+		t.appeared = make(map[string]time.Time)
+		t.vanished = make(map[string]vanishedProcess)
+	}
+
+	if t.seeded {
+		prev := make(map[string]bool, len(t.last))
+		for _, p := range t.last {
+			prev[p.ID] = true
+		}
+		curr := make(map[string]bool, len(procs))
+		for _, p := range procs {
+			curr[p.ID] = true
+			if !prev[p.ID] {
+				t.appeared[p.ID] = now
+				delete(t.vanished, p.ID)
+			}
+		}
+		for _, p := range t.last {
+			if !curr[p.ID] {
+				t.vanished[p.ID] = vanishedProcess{proc: p, at: now}
+				delete(t.appeared, p.ID)
+			}
+		}
+	}
+
+	for id, at := range t.appeared {
+		if t.progress(at, now) >= 1 {
+			delete(t.appeared, id)
+		}
+	}
+	for id, v := range t.vanished {
+		if t.progress(v.at, now) >= 1 {
+			delete(t.vanished, id)
+		}
+	}
+	t.last = procs
+	t.seeded = true
+}
+
+// progress is how far an animation that started at start has run by now,
+// from 0 to 1
+func (t *spawnTracker) progress(start, now time.Time) float64 {
+	return max(0, min(1, float64(now.Sub(start))/float64(t.duration)))
+}
+
+// at returns state with the animations running at now applied: vanished
+// processes are added back, and animating ones get a size and a fade
+func (t *spawnTracker) at(state State, now time.Time) State {
+	if len(t.appeared) == 0 && len(t.vanished) == 0 {
+		return state
+	}
+
+	s := &spawnState{
+		State:     state,
+		processes: state.Processes(),
+		scale:     make(map[string]float64),
+		alpha:     make(map[string]float64),
+	}
+	present := make(map[string]bool, len(s.processes))
+	for _, p := range s.processes {
+		present[p.ID] = true
+	}
+
+	for id, at := range t.appeared {
+		if p := t.progress(at, now); p < 1 {
+			s.scale[id] = easeOutBack(p)
+		}
+	}
+
+	// Sorted so vanishing processes keep their draw order between frames
+	ids := make([]string, 0, len(t.vanished))
+	for id := range t.vanished {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		v := t.vanished[id]
+		p := t.progress(v.at, now)
+		if p >= 1 {
+			continue
+		}
+		s.scale[id] = 1 - p*p
+		s.alpha[id] = 1 - p
+		// Interpolation may still be fading it out of its own accord
+		if !present[id] {
+			s.processes = append(s.processes, v.proc)
+		}
+	}
+	return s
+}
+
+// easeOutBack rises from 0 to 1, overshooting by about 10% on the way
+func easeOutBack(t float64) float64 {
+	const c1 = 1.70158
+	const c3 = c1 + 1
+	return 1 + c3*math.Pow(t-1, 3) + c1*math.Pow(t-1, 2)
+}
+
+// spawnState is the State handed to the render paths while processes are
+// spawning or despawning. It carries a size and fade per process.
+type spawnState struct {
+	State
+	processes []Process
+	scale     map[string]float64
+	alpha     map[string]float64
+}
+
+func (s *spawnState) Processes() []Process { return s.processes }
+
+func (s *spawnState) Edges() []Edge {
+	if es, ok := s.State.(EdgeState); ok {
+		return es.Edges()
+	}
+	return nil
+}
+
+// processScale returns the size a process is drawn at relative to normal,
+// 1 unless it is spawning or despawning
+func processScale(state State, id string) float64 {
+	if s, ok := state.(*trailState); ok {
+		state = s.State
+	}
+	if s, ok := state.(*spawnState); ok {
+		if v, ok := s.scale[id]; ok {
+			return v
+		}
+	}
+	return 1
+}
+
+// scaledRadius returns radius resized by scale, in whole pixels
+func scaledRadius(radius int, scale float64) int {
+	return int(math.Round(float64(radius) * scale))
+}
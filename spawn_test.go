@@ -0,0 +1,46 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"testing"
+	"time"
+)
+
+func TestSpawnAnimationScalesProcesses(t *testing.T) {
+	r := newTestRenderer(t, Options{Width: 320, Height: 240, FrameRate: 10, Manual: true})
+	r.SetSpawnAnimation(true, 400*time.Millisecond)
+	palette := DefaultPalette()
+	pixels := func(img image.Image, typ string) int {
+		return countColor(img.(*image.RGBA), img.Bounds(), palette.ProcessColor(typ))
+	}
+	tree := Process{ID: "p", Type: "tree"}
+	mana := Process{ID: "q", Type: "mana", X: 2}
+
+	r.Update(&staticState{processes: []Process{tree}})
+	full := pixels(r.Step(), "tree") // Present from the start, so no pop
+
+	r.Update(&staticState{processes: []Process{tree, mana}})
+	growing := pixels(r.Step(), "mana")
+	for i := 0; i < 4; i++ {
+		r.Step()
+	}
+	grown := pixels(r.Step(), "mana")
+	if growing == 0 || growing >= grown || grown != full {
+		t.Fatalf("spawning sprite has %d pixels, then %d, want it growing to %d", growing, grown, full)
+	}
+
+	r.Update(&staticState{processes: []Process{mana}})
+	shrinking := r.Step().(*image.RGBA)
+	if shrinking.RGBAAt(132, 132) == palette.Background {
+		t.Error("despawning sprite vanished at once")
+	}
+	if n := pixels(shrinking, "tree"); n >= full {
+		t.Errorf("despawning sprite has %d opaque pixels, want fewer than %d", n, full)
+	}
+	for i := 0; i < 4; i++ {
+		r.Step()
+	}
+	if n := pixels(r.Step(), "tree"); n != 0 {
+		t.Errorf("despawned sprite still has %d pixels after the animation", n)
+	}
+}
@@ -1,6 +1,8 @@
 package nimsforestsprites
 
 import (
+	"fmt"
+	"math"
 	"math/rand"
 	"sync"
 	"time"
@@ -16,10 +18,11 @@ type State interface {
 
 // Land represents a renderable land/node
 type Land struct {
-	ID   string
-	Name string
-	X, Y float64 // Grid position
-	Type string  // "normal", "mana", etc.
+	ID        string
+	Name      string
+	X, Y      float64 // Grid position
+	Type      string  // "normal", "mana", etc.
+	Elevation float64 // Height above the ground in quarter tiles (0 = flat)
 }
 
 // Process represents an active process (tree, nim, etc.)
@@ -29,6 +32,43 @@ type Process struct {
 	Type     string  // "tree", "nim", "mana", etc.
 	Progress float64 // 0.0 to 1.0
 	X, Y     float64 // Position within the land
+	Z        float64 // Draw order; higher draws on top (0 keeps state order)
+	Status   string  // "error", "stalled", etc. (empty = healthy)
+}
+
+// Neighbors returns the IDs of the lands at the four grid positions adjacent
+// to the land with the given ID, in up, right, down, left order. Grid
+// positions are rounded to the nearest cell. Unknown IDs return nil.
+func Neighbors(state State, landID string) []string {
+	if state == nil {
+		return nil
+	}
+
+	type cell struct{ x, y int }
+	lands := state.Lands()
+	byCell := make(map[cell]string, len(lands))
+
+	var origin cell
+	found := false
+	for _, land := range lands {
+		c := cell{int(math.Round(land.X)), int(math.Round(land.Y))}
+		byCell[c] = land.ID
+		if land.ID == landID {
+			origin = c
+			found = true
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	var result []string
+	for _, d := range []cell{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+		if id, ok := byCell[cell{origin.x + d.x, origin.y + d.y}]; ok {
+			result = append(result, id)
+		}
+	}
+	return result
 }
 
 // MockState provides fake state for demo/testing
@@ -41,8 +81,14 @@ type MockState struct {
 
 // NewMockState creates a new mock state with a grid of lands
 func NewMockState() *MockState {
+	return NewMockStateSeed(time.Now().UnixNano())
+}
+
+// NewMockStateSeed creates a mock state whose lands, processes, and
+// Randomize sequence are fully determined by seed
+func NewMockStateSeed(seed int64) *MockState {
 	m := &MockState{
-		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
+		rng: rand.New(rand.NewSource(seed)),
 	}
 	m.initializeLands()
 	m.initializeProcesses()
@@ -113,9 +159,21 @@ func (m *MockState) Processes() []Process {
 
 // Randomize updates the mock state with random changes
 func (m *MockState) Randomize() {
+	m.RandomizeWith(nil)
+}
+
+// RandomizeWith makes the same changes as Randomize, drawing from rng
+// instead of the state's own generator, so a seeded rng gives a
+// reproducible sequence whatever the state was created with. A nil rng
+// uses the state's own generator.
+func (m *MockState) RandomizeWith(rng *rand.Rand) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if rng == nil {
+		rng = m.rng
+	}
+
 	// Update process progress
 	for i := range m.processes {
 		m.processes[i].Progress += 0.1
@@ -124,34 +182,95 @@ func (m *MockState) Randomize() {
 		}
 
 		// Slightly move processes
-		m.processes[i].X += (m.rng.Float64() - 0.5) * 0.05
-		m.processes[i].Y += (m.rng.Float64() - 0.5) * 0.05
+		m.processes[i].X += (rng.Float64() - 0.5) * 0.05
+		m.processes[i].Y += (rng.Float64() - 0.5) * 0.05
 	}
 
 	// Occasionally add or remove a process
-	if m.rng.Float64() < 0.2 {
-		if len(m.processes) > 3 && m.rng.Float64() < 0.5 {
+	if rng.Float64() < 0.2 {
+		if len(m.processes) > 3 && rng.Float64() < 0.5 {
 			// Remove a random process
-			idx := m.rng.Intn(len(m.processes))
+			idx := rng.Intn(len(m.processes))
 			m.processes = append(m.processes[:idx], m.processes[idx+1:]...)
 		} else if len(m.processes) < 15 {
 			// Add a new process
 			processTypes := []string{"tree", "nim", "mana", "harvest"}
-			landIdx := m.rng.Intn(len(m.lands))
+			landIdx := rng.Intn(len(m.lands))
 			land := m.lands[landIdx]
 
 			m.processes = append(m.processes, Process{
-				ID:       generateProcessID(m.rng.Int()),
+				ID:       generateProcessID(rng.Int()),
 				LandID:   land.ID,
-				Type:     processTypes[m.rng.Intn(len(processTypes))],
+				Type:     processTypes[rng.Intn(len(processTypes))],
 				Progress: 0.0,
-				X:        land.X + (m.rng.Float64()-0.5)*0.5,
-				Y:        land.Y + (m.rng.Float64()-0.5)*0.5,
+				X:        land.X + (rng.Float64()-0.5)*0.5,
+				Y:        land.Y + (rng.Float64()-0.5)*0.5,
 			})
 		}
 	}
 }
 
+// SetProcess places a process of procType exactly on the land with the
+// given ID, with no random jitter, for building a specific scene. A
+// process of that type already on the land is updated instead of
+// duplicated. Progress is clamped to [0, 1]. It returns the process ID,
+// which RemoveProcess takes.
+func (m *MockState) SetProcess(landID, procType string, progress float64) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var land *Land
+	for i := range m.lands {
+		if m.lands[i].ID == landID {
+			land = &m.lands[i]
+			break
+		}
+	}
+	if land == nil {
+		return "", fmt.Errorf("unknown land %q", landID)
+	}
+
+	proc := Process{
+		ID:       "P-" + landID + "-" + procType,
+		LandID:   landID,
+		Type:     procType,
+		Progress: max(0, min(1, progress)),
+		X:        land.X,
+		Y:        land.Y,
+	}
+	for i := range m.processes {
+		if m.processes[i].ID == proc.ID {
+			m.processes[i] = proc
+			return proc.ID, nil
+		}
+	}
+	m.processes = append(m.processes, proc)
+	return proc.ID, nil
+}
+
+// RemoveProcess removes the process with the given ID and reports whether
+// it existed
+func (m *MockState) RemoveProcess(procID string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.processes {
+		if m.processes[i].ID == procID {
+			m.processes = append(m.processes[:i], m.processes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ClearProcesses removes every process, leaving the lands, so a scene can
+// be built from scratch with SetProcess
+func (m *MockState) ClearProcesses() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.processes = m.processes[:0]
+}
+
 // Helper functions for generating IDs and names
 func generateID(n int) string {
 	return string(rune('A'+n%26)) + string(rune('0'+n/26%10))
@@ -0,0 +1,69 @@
+package nimsforestsprites
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestMockStateSeedReproducible(t *testing.T) {
+	a, b := NewMockStateSeed(99), NewMockStateSeed(99)
+	for i := 0; i < 5; i++ {
+		a.Randomize()
+		b.Randomize()
+	}
+
+	if !reflect.DeepEqual(a.Lands(), b.Lands()) {
+		t.Error("same seed gave different lands")
+	}
+	if !reflect.DeepEqual(a.Processes(), b.Processes()) {
+		t.Error("same seed gave different processes")
+	}
+}
+
+func TestRandomizeWithSeededRandReproducible(t *testing.T) {
+	a, b := NewMockStateSeed(7), NewMockStateSeed(7)
+	ra, rb := rand.New(rand.NewSource(42)), rand.New(rand.NewSource(42))
+	for i := 0; i < 5; i++ {
+		a.RandomizeWith(ra)
+		b.RandomizeWith(rb)
+	}
+
+	if !reflect.DeepEqual(a.Processes(), b.Processes()) {
+		t.Error("equal rngs gave different processes")
+	}
+}
+
+func TestSetProcessBuildsExactScene(t *testing.T) {
+	m := NewMockStateSeed(1)
+	m.ClearProcesses()
+	lands := m.Lands()
+	a, b := lands[0], lands[len(lands)-1]
+
+	idA, err := m.SetProcess(a.ID, "tree", 0.25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idB, err := m.SetProcess(b.ID, "nim", 1.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Process{
+		{ID: idA, LandID: a.ID, Type: "tree", Progress: 0.25, X: a.X, Y: a.Y},
+		{ID: idB, LandID: b.ID, Type: "nim", Progress: 1, X: b.X, Y: b.Y},
+	}
+	if got := m.Processes(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Processes() = %+v, want %+v", got, want)
+	}
+
+	if !m.RemoveProcess(idA) {
+		t.Fatal("RemoveProcess did not find the first process")
+	}
+	if got := m.Processes(); !reflect.DeepEqual(got, want[1:]) {
+		t.Fatalf("after RemoveProcess, Processes() = %+v, want %+v", got, want[1:])
+	}
+	if _, err := m.SetProcess("no-such-land", "tree", 0); err == nil {
+		t.Fatal("expected an error for an unknown land")
+	}
+}
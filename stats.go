@@ -0,0 +1,108 @@
+package nimsforestsprites
+
+import (
+	"log"
+	"time"
+)
+
+// FrameStats describes one produced frame
+type FrameStats struct {
+	Tick           int
+	RenderDuration time.Duration
+	SpriteCount    int  // Processes in the rendered state
+	HiddenCount    int  // Processes left out by SetMaxVisibleProcesses
+	LandCount      int  // Lands in the rendered state
+	Dropped        bool // The frame was discarded because a channel was full
+}
+
+// OnFrame registers fn to be called after every frame produced by Render or
+// the Frames loop. Callbacks run in registration order on the rendering
+// goroutine, so they should return quickly. A panicking callback is
+// recovered and logged without stopping rendering.
+func (r *Renderer) OnFrame(fn func(stats FrameStats)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frameCallbacks = append(r.frameCallbacks, fn)
+}
+
+// emitFrameStats invokes the registered OnFrame callbacks
+func (r *Renderer) emitFrameStats(state State, tick int, d time.Duration, dropped bool) {
+	r.mu.RLock()
+	callbacks := r.frameCallbacks
+	limit := r.maxProcesses
+	r.mu.RUnlock()
+
+	if len(callbacks) == 0 {
+		return
+	}
+
+	stats := FrameStats{Tick: tick, RenderDuration: d, Dropped: dropped}
+	if state != nil {
+		stats.SpriteCount = len(state.Processes())
+		stats.LandCount = len(state.Lands())
+		if limit > 0 {
+			stats.HiddenCount = max(0, stats.SpriteCount-limit)
+		}
+	}
+
+	for _, fn := range callbacks {
+		callFrameCallback(fn, stats)
+	}
+}
+
+func callFrameCallback(fn func(FrameStats), stats FrameStats) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("nimsforestsprites: OnFrame callback panicked: %v", p)
+		}
+	}()
+	fn(stats)
+}
+
+// RenderStats summarizes the Frames loop's output
+type RenderStats struct {
+	InstantFPS     float64 // Rate implied by the gap before the latest frame
+	SmoothedFPS    float64 // Exponential moving average of InstantFPS
+	FramesProduced int64   // Frames emitted by the Frames loop
+	FramesDropped  int64   // Same as DroppedFrames
+
+	// Drift is how far the Frames loop has fallen behind its schedule,
+	// counting the frame intervals that passed without a frame.
+	// Options.CatchUp works it back down.
+	Drift time.Duration
+}
+
+// fpsSmoothing is the weight of the newest interval in SmoothedFPS
+const fpsSmoothing = 0.1
+
+// maxCatchUp caps the extra frames Options.CatchUp emits per tick, so a
+// long stall doesn't turn into a burst of thousands of frames
+const maxCatchUp = 10
+
+// Stats returns the Frames loop's frame rate and counters. The rates are 0
+// until two frames have been produced.
+func (r *Renderer) Stats() RenderStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return RenderStats{
+		InstantFPS:     r.instantFPS,
+		SmoothedFPS:    r.smoothFPS,
+		FramesProduced: r.produced.Load(),
+		FramesDropped:  r.dropped.Load(),
+		Drift:          r.drift,
+	}
+}
+
+// recordFrameIntervalLocked folds the time between two emitted frames
+// into the frame rate stats
+func (r *Renderer) recordFrameIntervalLocked(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	r.instantFPS = float64(time.Second) / float64(d)
+	if r.smoothFPS == 0 {
+		r.smoothFPS = r.instantFPS
+	} else {
+		r.smoothFPS += fpsSmoothing * (r.instantFPS - r.smoothFPS)
+	}
+}
@@ -0,0 +1,121 @@
+package nimsforestsprites
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestStatsCountFramesAndFPS(t *testing.T) {
+	const n = 6
+	clock := newFakeClock()
+	r := newTestRenderer(t, Options{FrameRate: 30, Clock: clock})
+	r.Update(NewMockStateSeed(1))
+	if s := r.Stats(); s != (RenderStats{}) {
+		t.Fatalf("Stats before any frame = %+v, want zero", s)
+	}
+
+	// OnFrame runs once a frame's counters are updated
+	counted := make(chan struct{}, n)
+	r.OnFrame(func(FrameStats) { counted <- struct{}{} })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	frames := r.Frames(ctx)
+	ticker := <-clock.tickers
+	for i := 0; i < n; i++ {
+		clock.fire(ticker)
+		<-frames
+		<-counted
+	}
+
+	// The fake clock only moves when a tick fires, so frames go out exactly
+	// one interval apart
+	s := r.Stats()
+	if s.FramesProduced != n || s.FramesDropped != 0 {
+		t.Errorf("FramesProduced = %d and FramesDropped = %d, want %d and 0", s.FramesProduced, s.FramesDropped, n)
+	}
+	if math.Abs(s.InstantFPS-30) > 0.01 || math.Abs(s.SmoothedFPS-30) > 0.01 {
+		t.Errorf("InstantFPS = %v and SmoothedFPS = %v, want 30", s.InstantFPS, s.SmoothedFPS)
+	}
+}
+
+func TestStatsReportDriftAfterStall(t *testing.T) {
+	for _, catchUp := range []bool{false, true} {
+		clock := newFakeClock()
+		r := newTestRenderer(t, Options{FrameRate: 30, Clock: clock, CatchUp: catchUp, FrameBuffer: 16})
+		r.Update(NewMockStateSeed(1))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		frames := r.Frames(ctx)
+		ticker := <-clock.tickers
+		clock.fire(ticker)
+		<-frames
+
+		// A stall: five intervals pass before the next tick is taken
+		clock.advance(5 * ticker.d)
+		clock.fire(ticker)
+		want, produced := 5*ticker.d, int64(2)
+		if catchUp {
+			want, produced = 0, 7
+		}
+		for i := int64(1); i < produced; i++ {
+			<-frames
+		}
+		// Stats are recorded after the frames for a tick go out; the loop
+		// takes the next tick only once that is done
+		clock.fire(ticker)
+
+		s := r.Stats()
+		if s.Drift != want || s.FramesProduced != produced {
+			t.Errorf("CatchUp %v: Drift = %v with %d frames, want %v with %d", catchUp, s.Drift, s.FramesProduced, want, produced)
+		}
+		cancel()
+	}
+}
+
+// slowState advances a fake clock whenever its lands are read, so renders
+// of it take that long as far as the renderer can tell
+type slowState struct {
+	staticState
+	clock *fakeClock
+	delay time.Duration
+}
+
+func (s *slowState) Lands() []Land {
+	s.clock.advance(s.delay)
+	return s.staticState.Lands()
+}
+
+func TestActualFrameRateReflectsSlowRenders(t *testing.T) {
+	clock := newFakeClock()
+	r := newTestRenderer(t, Options{FrameRate: 30, Clock: clock, AdaptiveScale: true})
+	r.Update(&slowState{clock: clock, delay: 100 * time.Millisecond})
+	if fps := r.ActualFrameRate(); fps != 0 {
+		t.Fatalf("ActualFrameRate before any frame = %v, want 0", fps)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	frames := r.Frames(ctx)
+	ticker := <-clock.tickers
+	for i := 0; i < 30; i++ {
+		clock.fire(ticker)
+		<-frames
+	}
+	// Each tick is taken only once the previous frame is fully handled
+	clock.fire(ticker)
+
+	// Renders take at least 100ms, so at most 10 fps is sustainable, and a
+	// second of frames that slow lowers the scale one notch
+	if fps := r.ActualFrameRate(); fps > 10 {
+		t.Errorf("ActualFrameRate = %v with 100ms renders, want at most 10", fps)
+	}
+	r.mu.RLock()
+	scale := r.scale
+	r.mu.RUnlock()
+	if scale != 0.8 {
+		t.Errorf("scale is %v after a second of slow frames, want 0.8", scale)
+	}
+}
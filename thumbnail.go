@@ -0,0 +1,94 @@
+package nimsforestsprites
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+)
+
+// RenderMulti renders state once, like Render, and returns it at each of
+// the given scales, downscaled with a box filter so every copy matches the
+// full frame exactly. A scale of 1 returns the rendered frame itself.
+// Scales must be in (0, 1].
+func (r *Renderer) RenderMulti(state State, scales []float64) ([]image.Image, error) {
+	for _, s := range scales {
+		if !(s > 0 && s <= 1) {
+			return nil, fmt.Errorf("scale %v is outside (0, 1]", s)
+		}
+	}
+
+	frame := r.Render(state)
+	if frame == nil {
+		return nil, ErrRendererClosed
+	}
+
+	var src *image.RGBA
+	images := make([]image.Image, len(scales))
+	for i, s := range scales {
+		if s == 1 {
+			images[i] = frame
+			continue
+		}
+		if src == nil {
+			src = toRGBA(frame)
+		}
+		images[i] = downscale(src, s)
+	}
+	return images, nil
+}
+
+// toRGBA returns img as an *image.RGBA with its origin at zero, copying
+// only when necessary
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
+
+// downscale shrinks src by scale, averaging the source pixels that each
+// destination pixel covers
+func downscale(src *image.RGBA, scale float64) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dw := max(1, int(math.Round(float64(sw)*scale)))
+	dh := max(1, int(math.Round(float64(sh)*scale)))
+	return resize(src, dw, dh)
+}
+
+// resize scales src to dw x dh, averaging the source pixels that each
+// destination pixel covers. Enlarged axes repeat the nearest pixel.
+func resize(src *image.RGBA, dw, dh int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for dy := 0; dy < dh; dy++ {
+		y0 := dy * sh / dh
+		y1 := max(y0+1, (dy+1)*sh/dh)
+		for dx := 0; dx < dw; dx++ {
+			x0 := dx * sw / dw
+			x1 := max(x0+1, (dx+1)*sw/dw)
+
+			var sum [4]int
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride+4*x0 : y*src.Stride+4*x1]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			n := (x1 - x0) * (y1 - y0)
+			o := dy*dst.Stride + 4*dx
+			for c := range sum {
+				dst.Pix[o+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,52 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"image"
+	"testing"
+)
+
+func TestRenderMultiHalfScale(t *testing.T) {
+	r := newTestRenderer(t, Options{Width: 320, Height: 240})
+	images, err := r.RenderMulti(NewMockStateSeed(1), []float64{1.0, 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, half := images[0].(*image.RGBA), images[1].(*image.RGBA)
+	if full.Bounds() != image.Rect(0, 0, 320, 240) || half.Bounds() != image.Rect(0, 0, 160, 120) {
+		t.Fatalf("got %v and %v, want 320x240 and 160x120", full.Bounds(), half.Bounds())
+	}
+
+	// Each thumbnail pixel averages a 2x2 block of the full frame
+	bg := DefaultPalette().Background
+	drawn := 0
+	for y := 0; y < 120; y++ {
+		for x := 0; x < 160; x++ {
+			if half.RGBAAt(x, y) != bg {
+				drawn++
+			}
+		}
+	}
+	if drawn == 0 {
+		t.Fatal("the half-scale copy is all background")
+	}
+	if !bytes.Equal(half.Pix, downscale(full, 0.5).Pix) {
+		t.Fatal("the half-scale copy was not downscaled from the full frame")
+	}
+
+	if _, err := r.RenderMulti(NewMockStateSeed(1), []float64{2}); err == nil {
+		t.Error("RenderMulti accepted a scale above 1")
+	}
+}
+
+func TestResizeAveragesBlocks(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	copy(src.Pix, []uint8{
+		0, 0, 0, 255, 100, 0, 0, 255,
+		0, 200, 0, 255, 0, 0, 40, 255,
+	})
+	got := resize(src, 1, 1).Pix
+	if want := []uint8{25, 50, 10, 255}; !bytes.Equal(got, want) {
+		t.Errorf("2x2 averaged to %v, want %v", got, want)
+	}
+}
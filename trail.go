@@ -0,0 +1,204 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"sort"
+	"time"
+)
+
+const (
+	defaultTrailLength = 8
+	maxTrailLength     = 64
+
+	// trailFadeFrames is how many frames the trail of a vanished process
+	// takes to fade out
+	trailFadeFrames = 4
+	trailAlpha      = 0.6 // Opacity of the newest trail segment
+	trailWidth      = 2
+)
+
+// SetTrailsEnabled draws a fading line behind each process through the
+// positions it had in its last updates, matched by ID across Update calls.
+// length is how many positions each trail keeps, clamped to [2, 64], with
+// 0 meaning 8. A process that moved fewer times than that has a shorter
+// trail, and one that just appeared has none. When a process disappears
+// its trail fades out over a few frames. Trails run between cell centers,
+// beneath every sprite.
+func (r *Renderer) SetTrailsEnabled(enabled bool, length int) {
+	if length <= 0 {
+		length = defaultTrailLength
+	}
+	length = max(2, min(maxTrailLength, length))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !enabled {
+		r.trails = nil
+		return
+	}
+	r.trails = &trailTracker{length: length, fade: trailFadeFrames * r.frameDuration()}
+	if r.state != nil {
+		r.trails.update(r.state, r.interpolationTimeLocked())
+	}
+}
+
+// trailPoint is a position a process was updated to
+type trailPoint struct {
+	X, Y   float64
+	LandID string
+}
+
+// trailTracker keeps the recent positions of each process between updates
+type trailTracker struct {
+	length int
+	fade   time.Duration
+
+	history  map[string][]trailPoint // Oldest first, the latest update last
+	types    map[string]string       // Process type, for the trail's color
+	vanished map[string]time.Time
+}
+
+// update appends each process's position in state to its history, unless
+// it has not moved. Histories of vanished processes are kept until their
+// fade has run by now.
+func (t *trailTracker) update(state State, now time.Time) {
+	if t.history == nil {
+		t.history = make(map[string][]trailPoint)
+		t.types = make(map[string]string)
+		t.vanished = make(map[string]time.Time)
+	}
+
+	present := make(map[string]bool)
+	if state != nil {
+		for _, p := range state.Processes() {
+			present[p.ID] = true
+			delete(t.vanished, p.ID)
+			t.types[p.ID] = p.Type
+
+			point := trailPoint{X: p.X, Y: p.Y, LandID: p.LandID}
+			h := t.history[p.ID]
+			if len(h) > 0 && h[len(h)-1] == point {
+				continue
+			}
+			// Frames may still be drawing earlier histories, so drop the
+			// oldest point by reslicing rather than shifting in place
+			if len(h) == t.length {
+				h = h[1:]
+			}
+			t.history[p.ID] = append(h, point)
+		}
+	}
+
+	for id := range t.history {
+		if present[id] {
+			continue
+		}
+		at, ok := t.vanished[id]
+		if !ok {
+			t.vanished[id] = now
+		} else if now.Sub(at) >= t.fade {
+			delete(t.history, id)
+			delete(t.types, id)
+			delete(t.vanished, id)
+		}
+	}
+}
+
+// trail is a polyline to draw behind a process, oldest point first
+type trail struct {
+	points []trailPoint
+	typ    string
+	alpha  float64 // Below 1 while the process's trail fades out
+}
+
+// at returns state with the trails to draw at now attached. The newest
+// point of a present process's trail is where state draws it, so trails
+// stay attached to interpolated sprites.
+func (t *trailTracker) at(state State, now time.Time) State {
+	drawn := make(map[string]Process)
+	for _, p := range state.Processes() {
+		drawn[p.ID] = p
+	}
+
+	ids := make([]string, 0, len(t.history))
+	for id := range t.history {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	s := &trailState{State: state}
+	for _, id := range ids {
+		h := t.history[id]
+		tr := trail{typ: t.types[id], alpha: 1}
+		if at, ok := t.vanished[id]; ok {
+			tr.alpha = 1 - max(0, min(1, float64(now.Sub(at))/float64(t.fade)))
+			tr.points = h
+		} else if p, ok := drawn[id]; ok {
+			tr.points = append(h[:len(h)-1:len(h)-1], trailPoint{X: p.X, Y: p.Y, LandID: p.LandID})
+		}
+		if len(tr.points) >= 2 && tr.alpha > 0 {
+			s.trails = append(s.trails, tr)
+		}
+	}
+	return s
+}
+
+// trailState is the State handed to the render paths while trails are
+// enabled
+type trailState struct {
+	State
+	trails []trail
+}
+
+func (s *trailState) Edges() []Edge {
+	if es, ok := s.State.(EdgeState); ok {
+		return es.Edges()
+	}
+	return nil
+}
+
+// processTrails returns the trails attached to state, if any
+func processTrails(state State) []trail {
+	if s, ok := state.(*trailState); ok {
+		return s.trails
+	}
+	return nil
+}
+
+// trailSegment is one line of a trail in pixels, with its opacity
+type trailSegment struct {
+	x0, y0, x1, y1 int
+	typ            string
+	alpha          float64
+}
+
+// trailSegments lays out the trails in state for a frame, fading each
+// trail's segments from its newest to its oldest
+func trailSegments(state State, tileSize int, lifts map[string]int) []trailSegment {
+	var segs []trailSegment
+	for _, tr := range processTrails(state) {
+		n := len(tr.points) - 1
+		for k := 0; k < n; k++ {
+			a, b := tr.points[k], tr.points[k+1]
+			segs = append(segs, trailSegment{
+				x0:    100 + int(a.X*float64(tileSize)) + tileSize/2,
+				y0:    100 + int(a.Y*float64(tileSize)) + tileSize/2 - lifts[a.LandID],
+				x1:    100 + int(b.X*float64(tileSize)) + tileSize/2,
+				y1:    100 + int(b.Y*float64(tileSize)) + tileSize/2 - lifts[b.LandID],
+				typ:   tr.typ,
+				alpha: trailAlpha * tr.alpha * float64(k+1) / float64(n),
+			})
+		}
+	}
+	return segs
+}
+
+// trailRects covers every trail in state, for RenderDelta to redraw
+func trailRects(state State, tileSize int, lifts map[string]int) []image.Rectangle {
+	var rects []image.Rectangle
+	for _, seg := range trailSegments(state, tileSize, lifts) {
+		rect := image.Rect(seg.x0, seg.y0, seg.x1, seg.y1).Canon().Inset(-trailWidth)
+		rects = append(rects, rect)
+	}
+	return rects
+}
@@ -0,0 +1,52 @@
+package nimsforestsprites
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestTrailsDrawBehindMovedProcess(t *testing.T) {
+	r := newTestRenderer(t, Options{Width: 400, Height: 240, Manual: true})
+	r.SetTrailsEnabled(true, 0)
+	bg := DefaultPalette().Background
+	at := func(x float64) State {
+		return &staticState{processes: []Process{{ID: "p", Type: "tree", X: x}}}
+	}
+
+	// Cell centers are 64 pixels apart on the row at y = 132. The segment
+	// into the cell the process left last is the older, fainter one.
+	r.Update(at(0))
+	if img := r.Step().(*image.RGBA); img.RGBAAt(150, 132) != bg {
+		t.Fatal("trail drawn for a process that has not moved")
+	}
+	r.Update(at(1))
+	r.Update(at(2))
+	img := r.Step().(*image.RGBA)
+	older, newer := img.RGBAAt(160, 132), img.RGBAAt(228, 132)
+	if older == bg || newer == bg {
+		t.Fatalf("trail pixels are %v and %v, want both drawn over the background %v", older, newer, bg)
+	}
+	tree := DefaultPalette().ProcessColor("tree")
+	if dist(newer, tree) >= dist(older, tree) {
+		t.Errorf("newer segment %v is no stronger than the older %v", newer, older)
+	}
+
+	// A vanished process's trail fades out over a few frames
+	r.Update(&staticState{})
+	if img := r.Step().(*image.RGBA); img.RGBAAt(228, 132) == bg {
+		t.Error("trail gone the frame after its process vanished")
+	}
+	for i := 0; i < trailFadeFrames; i++ {
+		img = r.Step().(*image.RGBA)
+	}
+	if got := img.RGBAAt(228, 132); got != bg {
+		t.Errorf("trail pixel is %v after fading out, want the background %v", got, bg)
+	}
+}
+
+// dist is the sum of the channel differences between two colors
+func dist(a, b color.RGBA) int {
+	abs := func(v int) int { return max(v, -v) }
+	return abs(int(a.R)-int(b.R)) + abs(int(a.G)-int(b.G)) + abs(int(a.B)-int(b.B))
+}
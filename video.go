@@ -0,0 +1,139 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// VideoOptions configures a VideoSink
+type VideoOptions struct {
+	Width     int // Frame width (default 1920)
+	Height    int // Frame height (default 1080)
+	FrameRate int // Output FPS, normally the renderer's (default 30)
+}
+
+// VideoSink encodes frames to an H.264 MP4 stream by piping raw RGBA
+// frames into an ffmpeg subprocess found on PATH. The MP4 is written
+// fragmented so it can be streamed to a non-seekable writer.
+type VideoSink struct {
+	opts    VideoOptions
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stderr  bytes.Buffer
+	scratch *image.RGBA
+	closed  bool
+}
+
+// NewVideoSink starts ffmpeg writing an MP4 to w. It returns an error if
+// ffmpeg is not installed so callers can fall back to EncodeGIF.
+func NewVideoSink(w io.Writer, opts VideoOptions) (*VideoSink, error) {
+	if opts.Width == 0 {
+		opts.Width = 1920
+	}
+	if opts.Height == 0 {
+		opts.Height = 1080
+	}
+	if opts.FrameRate == 0 {
+		opts.FrameRate = 30
+	}
+	if opts.Width%2 != 0 || opts.Height%2 != 0 {
+		return nil, fmt.Errorf("video size %dx%d must be even for H.264", opts.Width, opts.Height)
+	}
+
+	path, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg not found on PATH: %w", err)
+	}
+
+	s := &VideoSink{opts: opts}
+	s.cmd = exec.Command(path,
+		"-loglevel", "error",
+		"-f", "rawvideo",
+		"-pix_fmt", "rgba",
+		"-s", fmt.Sprintf("%dx%d", opts.Width, opts.Height),
+		"-r", strconv.Itoa(opts.FrameRate),
+		"-i", "pipe:0",
+		"-c:v", "libx264",
+		"-pix_fmt", "yuv420p",
+		"-movflags", "frag_keyframe+empty_moov",
+		"-f", "mp4",
+		"pipe:1",
+	)
+	s.cmd.Stdout = w
+	s.cmd.Stderr = &s.stderr
+
+	s.stdin, err = s.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start ffmpeg: %w", err)
+	}
+
+	return s, nil
+}
+
+// WriteFrame encodes one frame. The frame must match the configured size.
+func (s *VideoSink) WriteFrame(img image.Image) error {
+	if s.closed {
+		return errors.New("video sink is closed")
+	}
+
+	b := img.Bounds()
+	if b.Dx() != s.opts.Width || b.Dy() != s.opts.Height {
+		return fmt.Errorf("frame size %dx%d does not match video size %dx%d", b.Dx(), b.Dy(), s.opts.Width, s.opts.Height)
+	}
+
+	if _, err := s.stdin.Write(s.rawFrame(img)); err != nil {
+		// ffmpeg's stderr is only safe to read after Wait, which Close does
+		return fmt.Errorf("ffmpeg: %w", err)
+	}
+	return nil
+}
+
+// rawFrame returns the pixels of img, which must match the video size, as
+// the tightly packed RGBA rows ffmpeg reads. An *image.RGBA at the origin
+// is used in place; a sub-image's Pix can run on past its last row, so it
+// is cut to exactly one frame.
+func (s *VideoSink) rawFrame(img image.Image) []uint8 {
+	b := img.Bounds()
+	rgba, ok := img.(*image.RGBA)
+	if !ok || rgba.Stride != 4*s.opts.Width || b.Min != (image.Point{}) {
+		if s.scratch == nil {
+			s.scratch = image.NewRGBA(image.Rect(0, 0, s.opts.Width, s.opts.Height))
+		}
+		draw.Draw(s.scratch, s.scratch.Bounds(), img, b.Min, draw.Src)
+		rgba = s.scratch
+	}
+	return rgba.Pix[:4*s.opts.Width*s.opts.Height]
+}
+
+// Close flushes the remaining frames and waits for ffmpeg to finish
+func (s *VideoSink) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	s.stdin.Close()
+	if err := s.cmd.Wait(); err != nil {
+		return s.wrapErr(err)
+	}
+	return nil
+}
+
+// wrapErr adds ffmpeg's own error output to err when there is any. It must
+// only be called once the process has exited.
+func (s *VideoSink) wrapErr(err error) error {
+	if msg := strings.TrimSpace(s.stderr.String()); msg != "" {
+		return fmt.Errorf("ffmpeg: %w: %s", err, msg)
+	}
+	return fmt.Errorf("ffmpeg: %w", err)
+}
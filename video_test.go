@@ -0,0 +1,62 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"image"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestVideoSinkWritesMP4(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg not on PATH")
+	}
+
+	f, err := os.CreateTemp(t.TempDir(), "*.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	sink, err := NewVideoSink(f, VideoOptions{Width: 320, Height: 240, FrameRate: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newTestRenderer(t, Options{Width: 320, Height: 240, FrameRate: 10})
+	state := NewMockStateSeed(1)
+	for i := 0; i < 10; i++ {
+		state.Randomize()
+		if err := sink.WriteFrame(r.RenderAt(state, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) < 8 || string(data[4:8]) != "ftyp" {
+		t.Fatalf("output does not start with an MP4 ftyp box: % x", data[:min(len(data), 8)])
+	}
+}
+
+func TestVideoSinkRawFrameCutsSubImage(t *testing.T) {
+	s := &VideoSink{opts: VideoOptions{Width: 4, Height: 2}}
+
+	// Rows 0-1 of a taller image share its stride and start, but its Pix
+	// runs on through rows 2-3
+	parent := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range parent.Pix {
+		parent.Pix[i] = uint8(i)
+	}
+	sub := parent.SubImage(image.Rect(0, 0, 4, 2))
+
+	got := s.rawFrame(sub)
+	if want := parent.Pix[:4*4*2]; !bytes.Equal(got, want) {
+		t.Fatalf("raw frame is %d bytes, want the %d bytes of rows 0-1", len(got), len(want))
+	}
+}
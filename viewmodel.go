@@ -0,0 +1,58 @@
+package nimsforestsprites
+
+import "sync"
+
+// ViewModel is a thread-safe State that applications build up incrementally.
+// It can be filled from several goroutines while the renderer reads it.
+type ViewModel struct {
+	mu        sync.RWMutex
+	lands     []Land
+	processes []Process
+}
+
+// NewViewModel creates an empty view model
+func NewViewModel() *ViewModel {
+	return &ViewModel{}
+}
+
+// AddLand adds a land tile
+func (v *ViewModel) AddLand(land Land) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.lands = append(v.lands, land)
+}
+
+// AddProcess adds a process
+func (v *ViewModel) AddProcess(proc Process) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.processes = append(v.processes, proc)
+}
+
+// Clear removes all lands and processes
+func (v *ViewModel) Clear() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.lands = nil
+	v.processes = nil
+}
+
+// Lands returns a copy of the lands
+func (v *ViewModel) Lands() []Land {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	result := make([]Land, len(v.lands))
+	copy(result, v.lands)
+	return result
+}
+
+// Processes returns a copy of the processes
+func (v *ViewModel) Processes() []Process {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	result := make([]Process, len(v.processes))
+	copy(result, v.processes)
+	return result
+}
@@ -0,0 +1,45 @@
+package nimsforestsprites
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// Run with -race: building the model from several goroutines while it is
+// rendered must not race
+func TestViewModelConcurrentBuildAndRender(t *testing.T) {
+	r := newTestRenderer(t, Options{})
+	vm := NewViewModel()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				id := fmt.Sprintf("L%d-%d", g, i)
+				vm.AddLand(Land{ID: id, X: float64(i % 5), Y: float64(g), Type: "forest"})
+				vm.AddProcess(Process{ID: "P" + id, LandID: id, Type: "tree", X: float64(i % 5), Y: float64(g)})
+			}
+		}(g)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 10; i++ {
+			r.Render(vm)
+		}
+	}()
+	wg.Wait()
+
+	if n := len(vm.Lands()); n != 100 {
+		t.Fatalf("%d lands, want 100", n)
+	}
+	if n := len(vm.Processes()); n != 100 {
+		t.Fatalf("%d processes, want 100", n)
+	}
+	if r.Render(vm) == nil {
+		t.Fatal("Render returned no frame")
+	}
+}
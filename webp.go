@@ -0,0 +1,85 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// EncodeWebP writes img to w as lossy WebP at the given quality, clamped to
+// [0, 100]. Neither the standard library nor golang.org/x/image can encode
+// WebP, so this shells out to the cwebp tool, which must be on PATH.
+func EncodeWebP(w io.Writer, img image.Image, quality int) error {
+	quality = max(0, min(100, quality))
+
+	path, err := exec.LookPath("cwebp")
+	if err != nil {
+		return fmt.Errorf("cwebp not found on PATH: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "nimsforestsprites-webp")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "frame.png")
+	out := filepath.Join(dir, "frame.webp")
+	if err := writePNG(in, img); err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(path, "-quiet", "-q", strconv.Itoa(quality), in, "-o", out)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("cwebp: %w: %s", err, msg)
+		}
+		return fmt.Errorf("cwebp: %w", err)
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// SaveWebP writes img to filename as lossy WebP. See EncodeWebP.
+func SaveWebP(filename string, img image.Image, quality int) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := EncodeWebP(f, img, quality); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writePNG(filename string, img image.Image) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	enc := png.Encoder{CompressionLevel: png.BestSpeed}
+	if err := enc.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,70 @@
+package nimsforestsprites
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// webpSize reads the canvas size from a WebP file's header
+func webpSize(t *testing.T, data []byte) (int, int) {
+	t.Helper()
+	if len(data) < 30 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		t.Fatalf("missing RIFF WEBP header: % x", data[:min(len(data), 16)])
+	}
+	le24 := func(b []byte) int { return int(b[0]) | int(b[1])<<8 | int(b[2])<<16 }
+	switch chunk := string(data[12:16]); chunk {
+	case "VP8X":
+		return le24(data[24:]) + 1, le24(data[27:]) + 1
+	case "VP8 ":
+		return int(binary.LittleEndian.Uint16(data[26:]) & 0x3fff), int(binary.LittleEndian.Uint16(data[28:]) & 0x3fff)
+	case "VP8L":
+		bits := binary.LittleEndian.Uint32(data[21:])
+		return int(bits&0x3fff) + 1, int(bits>>14&0x3fff) + 1
+	default:
+		t.Fatalf("unknown first chunk %q", chunk)
+		return 0, 0
+	}
+}
+
+func TestEncodeWebPHeaderAndSize(t *testing.T) {
+	if _, err := exec.LookPath("cwebp"); err != nil {
+		t.Skip("cwebp not on PATH")
+	}
+	r := newTestRenderer(t, Options{Width: 320, Height: 240})
+	transparent := image.NewRGBA(image.Rect(0, 0, 64, 48))
+
+	for _, tc := range []struct {
+		name    string
+		img     image.Image
+		quality int
+	}{
+		{"mock frame", r.Render(NewMockStateSeed(1)), 80},
+		{"transparent", transparent, 150}, // Quality is clamped
+	} {
+		var buf bytes.Buffer
+		if err := EncodeWebP(&buf, tc.img, tc.quality); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		w, h := webpSize(t, buf.Bytes())
+		if b := tc.img.Bounds(); w != b.Dx() || h != b.Dy() {
+			t.Errorf("%s: WebP is %dx%d, want %dx%d", tc.name, w, h, b.Dx(), b.Dy())
+		}
+	}
+}
+
+func TestEncodeWebPWithoutCwebp(t *testing.T) {
+	if _, err := exec.LookPath("cwebp"); err == nil {
+		t.Skip("cwebp is on PATH")
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(0, 0, color.White)
+	err := EncodeWebP(&bytes.Buffer{}, img, 80)
+	if err == nil || !strings.Contains(err.Error(), "cwebp") {
+		t.Errorf("EncodeWebP without cwebp returned %v, want an error naming cwebp", err)
+	}
+}